@@ -0,0 +1,197 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AdaptiveConcurrency configures a concurrency limit that adjusts to the
+// service's load: the limit grows by one after a limit's worth of calls
+// complete without overload, and is multiplied by Backoff when a call is
+// rejected with 429 or 503, times out, or exceeds LatencyThreshold.
+type AdaptiveConcurrency struct {
+	// Initial is the starting limit. It defaults to Min.
+	Initial int
+	// Min is the lowest limit. It defaults to 1.
+	Min int
+	// Max is the highest limit. It defaults to 64.
+	Max int
+	// Backoff is the factor applied to the limit on overload. It defaults
+	// to 0.5.
+	Backoff float64
+	// LatencyThreshold, if set, treats successful attempts slower than it
+	// as overload.
+	LatencyThreshold time.Duration
+	// OnChange, if set, is called after every change of the limit.
+	OnChange func(LimitDecision)
+}
+
+// LimitDecision records a change of the adaptive concurrency limit.
+type LimitDecision struct {
+	Time     time.Time
+	Previous int
+	Limit    int
+	// Cause is the overload that triggered a decrease, or nil for an
+	// increase.
+	Cause error
+}
+
+// recentDecisions is the number of decisions reported by Client.Stats.
+const recentDecisions = 32
+
+// WithAdaptiveConcurrency limits the number of requests in flight with an
+// adaptive limit. It replaces WithMaxConcurrent, and ExtractBatch runs up to
+// the maximum limit of workers when BatchOptions.Concurrency is not set.
+func WithAdaptiveConcurrency(config AdaptiveConcurrency) ClientOption {
+	return func(c *Client) {
+		c.adaptive = newAdaptiveLimiter(config)
+		c.concurrency = c.adaptive.sem
+	}
+}
+
+// adaptiveLimiter resizes a semaphore with additive increase and
+// multiplicative decrease.
+type adaptiveLimiter struct {
+	config AdaptiveConcurrency
+	sem    *semaphore
+
+	mu        sync.Mutex
+	limit     int
+	successes int
+	// decreased is when the limit was last cut. Overload reported by
+	// attempts started earlier was caused by the old limit and is ignored.
+	decreased time.Time
+	decisions []LimitDecision
+}
+
+func newAdaptiveLimiter(config AdaptiveConcurrency) *adaptiveLimiter {
+	config.Min = max(config.Min, 1)
+	if config.Max <= 0 {
+		config.Max = 64
+	}
+	config.Max = max(config.Max, config.Min)
+	if config.Backoff <= 0 || config.Backoff >= 1 {
+		config.Backoff = 0.5
+	}
+	if config.Initial <= 0 {
+		config.Initial = config.Min
+	}
+	config.Initial = min(max(config.Initial, config.Min), config.Max)
+
+	return &adaptiveLimiter{
+		config: config,
+		sem:    newSemaphore(int64(config.Initial)),
+		limit:  config.Initial,
+	}
+}
+
+// observe adjusts the limit after an attempt that started at start and
+// failed with err, or succeeded if err is nil. It reports whether the limit
+// changed.
+func (a *adaptiveLimiter) observe(start time.Time, err error) (LimitDecision, bool) {
+	overload := overloadCause(err)
+	if overload == nil && err == nil && a.config.LatencyThreshold > 0 {
+		if latency := time.Since(start); latency > a.config.LatencyThreshold {
+			overload = &slowResponseError{latency: latency, threshold: a.config.LatencyThreshold}
+		}
+	}
+
+	a.mu.Lock()
+	decision := LimitDecision{Time: time.Now(), Previous: a.limit}
+	switch {
+	case overload != nil:
+		if start.Before(a.decreased) {
+			a.mu.Unlock()
+			return LimitDecision{}, false
+		}
+		a.limit = max(a.config.Min, int(float64(a.limit)*a.config.Backoff))
+		a.successes = 0
+		a.decreased = decision.Time
+		decision.Cause = overload
+	case err == nil || errors.As(err, new(*ClientError)):
+		// The service answered normally, even if it rejected the request.
+		a.successes++
+		if a.successes < a.limit || a.limit >= a.config.Max {
+			a.mu.Unlock()
+			return LimitDecision{}, false
+		}
+		a.limit++
+		a.successes = 0
+	default:
+		a.mu.Unlock()
+		return LimitDecision{}, false
+	}
+
+	if a.limit == decision.Previous {
+		a.mu.Unlock()
+		return LimitDecision{}, false
+	}
+	decision.Limit = a.limit
+	a.sem.resize(int64(a.limit))
+	a.decisions = append(a.decisions, decision)
+	if len(a.decisions) > recentDecisions {
+		a.decisions = a.decisions[len(a.decisions)-recentDecisions:]
+	}
+	a.mu.Unlock()
+
+	if a.config.OnChange != nil {
+		a.config.OnChange(decision)
+	}
+	return decision, true
+}
+
+// state returns the current limit and the recent decisions, oldest first.
+func (a *adaptiveLimiter) state() (int, []LimitDecision) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.limit, append([]LimitDecision(nil), a.decisions...)
+}
+
+// adapt reports an attempt to the adaptive limiter, if any.
+func (c *Client) adapt(ctx context.Context, start time.Time, err error) {
+	if c.adaptive == nil {
+		return
+	}
+	if decision, changed := c.adaptive.observe(start, err); changed {
+		attrs := []slog.Attr{
+			slog.Int("previous", decision.Previous),
+			slog.Int("limit", decision.Limit),
+		}
+		if decision.Cause != nil {
+			attrs = append(attrs, slog.Any("cause", decision.Cause))
+		}
+		c.logger().LogAttrs(ctx, slog.LevelDebug, "pdfclient concurrency limit changed", attrs...)
+	}
+}
+
+// overloadCause returns err if it shows that the service is overloaded.
+func overloadCause(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		switch clientErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return err
+		}
+	}
+	if errors.Is(err, ErrTimeout) {
+		return err
+	}
+	return nil
+}
+
+type slowResponseError struct {
+	latency   time.Duration
+	threshold time.Duration
+}
+
+func (e *slowResponseError) Error() string {
+	return fmt.Sprintf("response took %v, over the %v latency threshold", e.latency, e.threshold)
+}
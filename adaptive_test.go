@@ -0,0 +1,121 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestAdaptiveConcurrency_Increase(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	var changes []pdfclient.LimitDecision
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithAdaptiveConcurrency(pdfclient.AdaptiveConcurrency{
+		Initial:  1,
+		Max:      4,
+		OnChange: func(d pdfclient.LimitDecision) { changes = append(changes, d) },
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// The limit grows by one after a limit's worth of successes: 1 + 2 + 3
+	// calls take it from 1 to 4, where it stays.
+	for i := 0; i < 10; i++ {
+		if _, err := client.HealthCheck(context.Background()); err != nil {
+			t.Fatalf("HealthCheck() error = %v", err)
+		}
+	}
+
+	stats := client.Stats()
+	if stats.ConcurrencyLimit != 4 {
+		t.Errorf("Stats() concurrency limit = %v, want %v", stats.ConcurrencyLimit, 4)
+	}
+	if len(stats.LimitDecisions) != 3 || len(changes) != 3 {
+		t.Fatalf("limit decisions = %v, OnChange calls = %v, want 3 of each", stats.LimitDecisions, changes)
+	}
+	for i, d := range stats.LimitDecisions {
+		if d.Previous != i+1 || d.Limit != i+2 || d.Cause != nil {
+			t.Errorf("decision %d = %+v, want increase from %d to %d", i, d, i+1, i+2)
+		}
+	}
+}
+
+func TestAdaptiveConcurrency_Decrease(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.Latency(30*time.Millisecond)),
+		pdfclienttest.WithFault(pdfclienttest.ServerError(503), pdfclienttest.OnFirst(8)),
+	)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithAdaptiveConcurrency(pdfclient.AdaptiveConcurrency{
+		Initial: 8,
+		Min:     2,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.HealthCheck(context.Background()); !errors.Is(err, pdfclient.ErrServerUnavailable) {
+				t.Errorf("HealthCheck() error = %v, want ErrServerUnavailable", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// All eight requests were sent at the old limit, so they cause a
+	// single cut.
+	stats := client.Stats()
+	if stats.ConcurrencyLimit != 4 {
+		t.Errorf("Stats() concurrency limit = %v, want %v", stats.ConcurrencyLimit, 4)
+	}
+	if len(stats.LimitDecisions) != 1 {
+		t.Fatalf("limit decisions = %v, want 1", stats.LimitDecisions)
+	}
+
+	decision := stats.LimitDecisions[0]
+	if decision.Previous != 8 || decision.Limit != 4 || !errors.Is(decision.Cause, pdfclient.ErrServerUnavailable) {
+		t.Errorf("decision = %+v, want a cut from 8 to 4 caused by a 503", decision)
+	}
+
+	// Later overload halves the limit again, down to Min.
+	server.AddFault(pdfclienttest.ServerError(503))
+	for i := 0; i < 2; i++ {
+		client.HealthCheck(context.Background())
+	}
+	if limit := client.Stats().ConcurrencyLimit; limit != 2 {
+		t.Errorf("Stats() concurrency limit = %v, want %v", limit, 2)
+	}
+}
+
+func TestAdaptiveConcurrency_LatencyThreshold(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.Latency(30 * time.Millisecond)))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithAdaptiveConcurrency(pdfclient.AdaptiveConcurrency{
+		Initial:          4,
+		LatencyThreshold: 10 * time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+
+	if limit := client.Stats().ConcurrencyLimit; limit != 2 {
+		t.Errorf("Stats() concurrency limit = %v, want %v", limit, 2)
+	}
+}
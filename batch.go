@@ -0,0 +1,181 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is the number of concurrent extractions used by
+// ExtractBatch when BatchOptions.Concurrency is not set.
+const DefaultBatchConcurrency = 4
+
+// ErrBatchAborted is reported for batch inputs that were never sent because
+// the batch was cancelled or stopped by a fail-fast error.
+var ErrBatchAborted = errors.New("batch aborted before input was processed")
+
+// BatchInput is a single document in a batch. Exactly one of Path, Data or
+// Reader should be set; use FileInput, BytesInput or ReaderInput.
+type BatchInput struct {
+	Path     string
+	Data     []byte
+	Reader   io.Reader
+	FileName string
+}
+
+// FileInput returns a BatchInput reading the file at path.
+func FileInput(path string) BatchInput {
+	return BatchInput{Path: path, FileName: filepath.Base(path)}
+}
+
+// BytesInput returns a BatchInput uploading data as fileName.
+func BytesInput(data []byte, fileName string) BatchInput {
+	return BatchInput{Data: data, FileName: fileName}
+}
+
+// ReaderInput returns a BatchInput uploading the contents of reader as
+// fileName. The reader is not closed.
+func ReaderInput(reader io.Reader, fileName string) BatchInput {
+	return BatchInput{Reader: reader, FileName: fileName}
+}
+
+type BatchOptions struct {
+	// Concurrency is the maximum number of extractions in flight. It
+	// defaults to DefaultBatchConcurrency, or with WithAdaptiveConcurrency
+	// to the maximum adaptive limit, leaving the client to pace the batch.
+	Concurrency int
+	// FailFast stops the batch on the first failed input. Inputs not yet
+	// started are reported with ErrBatchAborted.
+	FailFast bool
+	// CallOptions are applied to every extraction in the batch.
+	CallOptions []CallOption
+	// Results, if set, receives every result as soon as it is available.
+	// ExtractBatch closes the channel before returning, so the caller must
+	// keep receiving until it is closed.
+	Results chan<- BatchResult
+}
+
+// BatchResult is the outcome of extracting a single BatchInput.
+type BatchResult struct {
+	// Index is the position of the input in the slice passed to ExtractBatch.
+	Index    int
+	FileName string
+	Response *TextExtractionResponse
+	Err      error
+	Started  time.Time
+	Duration time.Duration
+}
+
+// ExtractBatch extracts text from inputs with bounded concurrency. The
+// returned slice holds one result per input, in input order, even when the
+// context is cancelled part way through. The error is the first failure in
+// fail-fast mode, the context error if the batch was cancelled, and nil
+// otherwise; per-input failures are reported on the results.
+func (c *Client) ExtractBatch(ctx context.Context, inputs []BatchInput, opts BatchOptions) ([]BatchResult, error) {
+	if opts.Results != nil {
+		defer close(opts.Results)
+	}
+
+	results := make([]BatchResult, len(inputs))
+	err := c.runParallel(ctx, len(inputs), opts.Concurrency, func(ctx context.Context, i int, fail func(error)) {
+		result := c.extractBatchInput(ctx, i, inputs[i], opts.CallOptions)
+		results[i] = result
+
+		if result.Err != nil && opts.FailFast && !errors.Is(result.Err, ErrBatchAborted) {
+			fail(result.Err)
+		}
+
+		if opts.Results != nil {
+			opts.Results <- result
+		}
+	})
+	if err != nil {
+		return results, err
+	}
+
+	return results, ctx.Err()
+}
+
+// runParallel calls work for each index in [0, n) on up to concurrency
+// goroutines, defaulting as described on BatchOptions.Concurrency. The first
+// error passed to fail cancels the context given to the other calls and is
+// returned once they finish.
+func (c *Client) runParallel(ctx context.Context, n, concurrency int, work func(ctx context.Context, i int, fail func(error))) error {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+		if c.adaptive != nil {
+			concurrency = c.adaptive.config.Max
+		}
+	}
+	concurrency = min(concurrency, n)
+
+	workCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	jobs := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		failOnce sync.Once
+		failErr  error
+	)
+	fail := func(err error) {
+		failOnce.Do(func() {
+			failErr = err
+			cancel(err)
+		})
+	}
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				work(workCtx, i, fail)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return failErr
+}
+
+func (c *Client) extractBatchInput(ctx context.Context, index int, input BatchInput, opts []CallOption) BatchResult {
+	result := BatchResult{
+		Index:    index,
+		FileName: input.FileName,
+		Started:  time.Now(),
+	}
+
+	if result.FileName == "" && input.Path != "" {
+		result.FileName = filepath.Base(input.Path)
+	}
+
+	if ctx.Err() != nil {
+		result.Err = fmt.Errorf("%w: %w", ErrBatchAborted, context.Cause(ctx))
+		return result
+	}
+
+	switch {
+	case input.Path != "":
+		result.Response, result.Err = c.ExtractTextFromFile(ctx, input.Path, opts...)
+	case input.Data != nil:
+		result.Response, result.Err = c.ExtractTextFromBytes(ctx, input.Data, result.FileName, opts...)
+	case input.Reader != nil:
+		result.Response, result.Err = c.ExtractTextFromReader(ctx, input.Reader, result.FileName, opts...)
+	default:
+		result.Err = errors.New("batch input has no path, data or reader")
+	}
+
+	result.Duration = time.Since(result.Started)
+	return result
+}
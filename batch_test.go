@@ -0,0 +1,187 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+// newBatchServer returns a server that echoes the uploaded file name and
+// rejects files named "bad.pdf". It records the peak number of concurrent
+// requests.
+func newBatchServer(t *testing.T, inFlight, peak *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		_, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if header.Filename == "bad.pdf" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"detail":"Invalid PDF format"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"pages":[{"page":1,"text":"` + header.Filename + `"}],"page_count":1,"file_name":"` + header.Filename + `","file_size":3}`))
+	}))
+}
+
+func TestExtractBatch_MixedInputs(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := newBatchServer(t, &inFlight, &peak)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	tempFile := filepath.Join(t.TempDir(), "file.pdf")
+	if err := os.WriteFile(tempFile, []byte("pdf"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	inputs := []pdfclient.BatchInput{
+		pdfclient.FileInput(tempFile),
+		pdfclient.BytesInput([]byte("pdf"), "bytes.pdf"),
+		pdfclient.BytesInput([]byte("pdf"), "bad.pdf"),
+		pdfclient.ReaderInput(io.MultiReader(bytes.NewReader([]byte("pdf"))), "reader.pdf"),
+	}
+
+	results, err := client.ExtractBatch(context.Background(), inputs, pdfclient.BatchOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ExtractBatch() error = %v", err)
+	}
+
+	if len(results) != len(inputs) {
+		t.Fatalf("ExtractBatch() results = %v, want %v", len(results), len(inputs))
+	}
+
+	for i, want := range []string{"file.pdf", "bytes.pdf", "", "reader.pdf"} {
+		result := results[i]
+		if result.Index != i {
+			t.Errorf("results[%d].Index = %v", i, result.Index)
+		}
+		if want == "" {
+			var clientErr pdfclient.ClientError
+			if !errors.As(result.Err, &clientErr) || !clientErr.IsInvalidPDFError() {
+				t.Errorf("results[%d].Err = %v, want invalid PDF error", i, result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("results[%d].Err = %v", i, result.Err)
+			continue
+		}
+		if got := result.Response.GetFullText(); got != want {
+			t.Errorf("results[%d] text = %v, want %v", i, got, want)
+		}
+	}
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %v, want at most 2", got)
+	}
+}
+
+func TestExtractBatch_FailFast(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := newBatchServer(t, &inFlight, &peak)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	inputs := []pdfclient.BatchInput{
+		pdfclient.BytesInput([]byte("pdf"), "bad.pdf"),
+		pdfclient.BytesInput([]byte("pdf"), "a.pdf"),
+		pdfclient.BytesInput([]byte("pdf"), "b.pdf"),
+	}
+
+	stream := make(chan pdfclient.BatchResult)
+	received := make(chan int)
+	go func() {
+		n := 0
+		for range stream {
+			n++
+		}
+		received <- n
+	}()
+
+	results, err := client.ExtractBatch(context.Background(), inputs, pdfclient.BatchOptions{
+		Concurrency: 1,
+		FailFast:    true,
+		Results:     stream,
+	})
+
+	var clientErr pdfclient.ClientError
+	if !errors.As(err, &clientErr) {
+		t.Fatalf("ExtractBatch() error = %v, want ClientError", err)
+	}
+
+	for i := 1; i < len(results); i++ {
+		if !errors.Is(results[i].Err, pdfclient.ErrBatchAborted) {
+			t.Errorf("results[%d].Err = %v, want ErrBatchAborted", i, results[i].Err)
+		}
+	}
+
+	if n := <-received; n != len(inputs) {
+		t.Errorf("streamed results = %v, want %v", n, len(inputs))
+	}
+}
+
+func TestExtractBatch_CancelledContext(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := newBatchServer(t, &inFlight, &peak)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	inputs := []pdfclient.BatchInput{
+		pdfclient.BytesInput([]byte("pdf"), "a.pdf"),
+		pdfclient.BytesInput([]byte("pdf"), "b.pdf"),
+	}
+
+	results, err := client.ExtractBatch(ctx, inputs, pdfclient.BatchOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ExtractBatch() error = %v, want context.Canceled", err)
+	}
+
+	if len(results) != len(inputs) {
+		t.Fatalf("ExtractBatch() results = %v, want %v", len(results), len(inputs))
+	}
+
+	for i, result := range results {
+		if !errors.Is(result.Err, pdfclient.ErrBatchAborted) {
+			t.Errorf("results[%d].Err = %v, want ErrBatchAborted", i, result.Err)
+		}
+	}
+}
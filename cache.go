@@ -0,0 +1,249 @@
+package pdfclient
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Cache stores extraction results keyed by the content of the PDF and the
+// options it was extracted with. Implementations must be safe for
+// concurrent use.
+type Cache interface {
+	// Get returns the entry stored under key, or nil if there is none.
+	Get(ctx context.Context, key string) (*CacheEntry, error)
+	// Set stores entry under key.
+	Set(ctx context.Context, key string, entry *CacheEntry) error
+}
+
+// CacheEntry is a cached extraction result.
+type CacheEntry struct {
+	Response *TextExtractionResponse `json:"response"`
+	// ServerVersion is the version reported by the service's health check
+	// when the entry was stored.
+	ServerVersion string    `json:"server_version"`
+	Created       time.Time `json:"created"`
+	// Expires is when the entry stops being used. It is zero for entries
+	// without a TTL.
+	Expires time.Time `json:"expires"`
+}
+
+// Expired reports whether the entry has expired at now.
+func (e *CacheEntry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// CacheOptions configures WithCache.
+type CacheOptions struct {
+	// TTL is how long new entries are used. Zero keeps them until the cache
+	// evicts them.
+	TTL time.Duration
+	// MatchServerVersion ignores entries stored when the service reported a
+	// different version, so results are refreshed after an upgrade. The
+	// version is discovered with a health check; without this option, no
+	// check is made for the cache.
+	MatchServerVersion bool
+}
+
+// WithCache caches the results of ExtractTextFromFile, ExtractTextFromBytes
+// and ExtractTextFromReader. Entries are keyed on the SHA-256 of the PDF and
+// the method and output format call options; a hit returns without
+// uploading the file, and is reported to observers with
+// ResponseInfo.CacheHit set.
+func WithCache(cache Cache, options CacheOptions) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+		c.cacheOptions = options
+	}
+}
+
+// contentKey returns the cache key of the content of reader extracted with
+// options and the size of the content, leaving reader at its starting
+// offset. It returns "" if reader is not seekable.
+func contentKey(reader io.Reader, options callOptions) (string, int64, error) {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return "", 0, nil
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, nil
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return "", 0, fmt.Errorf("error hashing file data: %w", err)
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("error rewinding file data: %w", err)
+	}
+
+	key := fmt.Sprintf("sha256:%s:method=%s:output_format=%s",
+		hex.EncodeToString(hash.Sum(nil)), options.method, options.outputFormat)
+	if options.pages != "" {
+		key += ":pages=" + options.pages
+	}
+	return key, size, nil
+}
+
+// cachedExtraction returns the cached result for key, if there is a usable
+// one.
+func (c *Client) cachedExtraction(ctx context.Context, key, fileName string) *TextExtractionResponse {
+	entry, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.logger().Error("Failed to read cache", "key", key, "error", err)
+		return nil
+	}
+	if entry == nil || entry.Response == nil || entry.Expired(time.Now()) {
+		return nil
+	}
+	if c.cacheOptions.MatchServerVersion && entry.ServerVersion != c.serverVersion(ctx) {
+		return nil
+	}
+
+	result := *entry.Response
+	result.Pages = slices.Clone(result.Pages)
+	result.FileName = fileName
+	result.CacheHit = true
+	result.Endpoint = ""
+	return &result
+}
+
+// observeCacheHit reports an extraction that started at start and was
+// served from the cache to the client's observers, as a call without
+// attempts.
+func (c *Client) observeCacheHit(ctx context.Context, start time.Time, result *TextExtractionResponse) {
+	info := ResponseInfo{
+		RequestInfo: RequestInfo{
+			Operation: OpExtract,
+			Method:    http.MethodPost,
+			URL:       c.BaseURL + "/extract",
+			FileName:  result.FileName,
+		},
+		CacheHit:  true,
+		PageCount: result.PageCount,
+	}
+
+	observers := c.observers()
+	for _, o := range observers {
+		ctx = o.OnRequestStart(ctx, info.RequestInfo)
+	}
+	info.Duration = time.Since(start)
+	for _, o := range observers {
+		o.OnResponse(ctx, info)
+	}
+}
+
+// cacheExtraction stores result under key.
+func (c *Client) cacheExtraction(ctx context.Context, key string, result *TextExtractionResponse) {
+	response := *result
+	response.Pages = slices.Clone(result.Pages)
+
+	entry := &CacheEntry{
+		Response:      &response,
+		ServerVersion: c.serverVersion(ctx),
+		Created:       time.Now(),
+	}
+	if c.cacheOptions.TTL > 0 {
+		entry.Expires = entry.Created.Add(c.cacheOptions.TTL)
+	}
+
+	if err := c.cache.Set(ctx, key, entry); err != nil {
+		c.logger().Error("Failed to write cache", "key", key, "error", err)
+	}
+}
+
+// serverVersion returns the version reported by the service to store with a
+// cache entry. It is only looked up, with a health check, when entries must
+// match it; otherwise it is "" unless the version is already known.
+func (c *Client) serverVersion(ctx context.Context) string {
+	if !c.cacheOptions.MatchServerVersion {
+		return c.knownVersion()
+	}
+	version, _ := c.discoverVersion(ctx)
+	return version
+}
+
+// knownVersion returns the version reported by the service, or "" if it has
+// not been discovered. It sends no requests.
+func (c *Client) knownVersion() string {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	return c.version
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entries beyond a maximum count.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        list.List // of *memoryCacheItem, most recently used first
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache returns a MemoryCache holding at most maxEntries entries.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: max(maxEntries, 1),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryCache) Get(_ context.Context, key string) (*CacheEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+
+	item := elem.Value.(*memoryCacheItem)
+	if item.entry.Expired(time.Now()) {
+		m.lru.Remove(elem)
+		delete(m.entries, key)
+		return nil, nil
+	}
+
+	m.lru.MoveToFront(elem)
+	return item.entry, nil
+}
+
+func (m *MemoryCache) Set(_ context.Context, key string, entry *CacheEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		m.lru.MoveToFront(elem)
+		return nil
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for m.lru.Len() > m.maxEntries {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the cache.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
@@ -0,0 +1,308 @@
+package pdfclient_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func countRequests(server *pdfclienttest.Server, path string) int {
+	n := 0
+	for _, request := range server.Requests() {
+		if request.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCache_Hit(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithPages("a.pdf", "cached text"))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithCache(pdfclient.NewMemoryCache(10), pdfclient.CacheOptions{}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	first, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf")
+	if err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+	if first.CacheHit {
+		t.Errorf("first ExtractTextFromBytes() cache hit = %v, want %v", first.CacheHit, false)
+	}
+
+	second, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "b.pdf")
+	if err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+	if !second.CacheHit {
+		t.Errorf("second ExtractTextFromBytes() cache hit = %v, want %v", second.CacheHit, true)
+	}
+	if second.GetFullText() != "cached text" {
+		t.Errorf("second ExtractTextFromBytes() text = %q, want %q", second.GetFullText(), "cached text")
+	}
+	if second.FileName != "b.pdf" {
+		t.Errorf("second ExtractTextFromBytes() file name = %v, want %v", second.FileName, "b.pdf")
+	}
+
+	if n := countRequests(server, "/extract"); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+
+	// Results are copies, so callers cannot change the cached entry.
+	second.Pages[0].Text = "changed"
+	third, _ := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf")
+	if third.GetFullText() != "cached text" {
+		t.Errorf("third ExtractTextFromBytes() text = %q, want %q", third.GetFullText(), "cached text")
+	}
+
+	// Other options are cached separately.
+	if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf", pdfclient.WithMethod("pdfplumber")); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+	if n := countRequests(server, "/extract"); n != 2 {
+		t.Errorf("server received %d uploads, want %d", n, 2)
+	}
+}
+
+func TestCache_Observed(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithPages("a.pdf", "cached text"))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithCache(pdfclient.NewMemoryCache(10), pdfclient.CacheOptions{}),
+		pdfclient.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf"); err != nil {
+			t.Fatalf("ExtractTextFromBytes() error = %v", err)
+		}
+	}
+
+	stats := client.Stats().Operations[pdfclient.OpExtract]
+	if stats.Requests != 2 || stats.Succeeded != 2 || stats.CacheHits != 1 || stats.Pages != 2 {
+		t.Errorf("Stats() extract = %+v, want 2 successful requests of which 1 cache hit", stats)
+	}
+	if stats.Latency.Count != 1 {
+		t.Errorf("Stats() extract latency count = %v, want %v", stats.Latency.Count, 1)
+	}
+
+	responses := observer.responses
+	if len(responses) != 2 || responses[0].CacheHit || !responses[1].CacheHit || responses[1].Attempts != 0 {
+		t.Errorf("observed responses = %+v, want a request and then a cache hit", responses)
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithCache(pdfclient.NewMemoryCache(10), pdfclient.CacheOptions{TTL: 20 * time.Millisecond}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf"); err != nil {
+			t.Fatalf("ExtractTextFromBytes() error = %v", err)
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	result, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf")
+	if err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+	if result.CacheHit {
+		t.Errorf("ExtractTextFromBytes() after TTL cache hit = %v, want %v", result.CacheHit, false)
+	}
+	if n := countRequests(server, "/extract"); n != 2 {
+		t.Errorf("server received %d uploads, want %d", n, 2)
+	}
+}
+
+func TestCache_ServerVersion(t *testing.T) {
+	cache := pdfclient.NewMemoryCache(10)
+
+	old := pdfclienttest.NewServer(pdfclienttest.WithVersion("1.0.0"))
+	defer old.Close()
+	upgraded := pdfclienttest.NewServer(pdfclienttest.WithVersion("2.0.0"))
+	defer upgraded.Close()
+
+	// The entry stored by the old version is not used by the upgraded one,
+	// and the upgraded entry is not used by the old version.
+	options := pdfclient.CacheOptions{MatchServerVersion: true}
+	for i, server := range []*pdfclienttest.Server{old, upgraded, upgraded, old} {
+		client, err := pdfclient.NewClient(server.URL, pdfclient.WithCache(cache, options))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		result, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf")
+		if err != nil {
+			t.Fatalf("ExtractTextFromBytes() error = %v", err)
+		}
+		if wantHit := i == 2; result.CacheHit != wantHit {
+			t.Errorf("ExtractTextFromBytes() %d cache hit = %v, want %v", i, result.CacheHit, wantHit)
+		}
+	}
+}
+
+func TestCache_ServerVersionLookup(t *testing.T) {
+	for _, match := range []bool{false, true} {
+		t.Run(fmt.Sprintf("MatchServerVersion=%v", match), func(t *testing.T) {
+			server := pdfclienttest.NewServer(
+				pdfclienttest.WithFault(pdfclienttest.ServerError(http.StatusInternalServerError), pdfclienttest.OnPath("/health")),
+			)
+			defer server.Close()
+
+			options := pdfclient.CacheOptions{MatchServerVersion: match}
+			client, err := pdfclient.NewClient(server.URL, pdfclient.WithCache(pdfclient.NewMemoryCache(10), options))
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			// The version is only looked up when entries must match it, and a
+			// failed lookup is not repeated for every extraction.
+			for i := 0; i < 5; i++ {
+				data := []byte(fmt.Sprintf("%s %d", testPDF, i))
+				if _, err := client.ExtractTextFromBytes(context.Background(), data, "a.pdf"); err != nil {
+					t.Fatalf("ExtractTextFromBytes() error = %v", err)
+				}
+			}
+
+			want := 0
+			if match {
+				want = 1
+			}
+			if n := countRequests(server, "/health"); n != want {
+				t.Errorf("server received %d health checks, want %d", n, want)
+			}
+		})
+	}
+}
+
+func TestCache_NonSeekableReader(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	cache := pdfclient.NewMemoryCache(10)
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithCache(cache, pdfclient.CacheOptions{}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		reader := io.MultiReader(strings.NewReader(testPDF))
+		if _, err := client.ExtractTextFromReader(context.Background(), reader, "a.pdf"); err != nil {
+			t.Fatalf("ExtractTextFromReader() error = %v", err)
+		}
+	}
+
+	// Readers that are not seekable are spooled, so they are cached too.
+	if cache.Len() != 1 {
+		t.Errorf("cache entries = %v, want %v", cache.Len(), 1)
+	}
+	if n := countRequests(server, "/extract"); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+}
+
+func TestMemoryCache_Eviction(t *testing.T) {
+	cache := pdfclient.NewMemoryCache(2)
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b"} {
+		cache.Set(ctx, key, &pdfclient.CacheEntry{Response: &pdfclient.TextExtractionResponse{FileName: key}})
+	}
+	// Using "a" makes "b" the least recently used entry.
+	cache.Get(ctx, "a")
+	cache.Set(ctx, "c", &pdfclient.CacheEntry{Response: &pdfclient.TextExtractionResponse{FileName: "c"}})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		entry, err := cache.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", key, err)
+		}
+		if (entry != nil) != want {
+			t.Errorf("Get(%q) = %v, want present = %v", key, entry, want)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithPages("report.pdf", "on disk"))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(path, []byte(testPDF), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// Each client opens the directory afresh, as separate processes would.
+	for i, wantHit := range []bool{false, true} {
+		cache, err := pdfclient.NewDiskCache(filepath.Join(dir, "cache"))
+		if err != nil {
+			t.Fatalf("NewDiskCache() error = %v", err)
+		}
+		client, err := pdfclient.NewClient(server.URL, pdfclient.WithCache(cache, pdfclient.CacheOptions{}))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		result, err := client.ExtractTextFromFile(context.Background(), path)
+		if err != nil {
+			t.Fatalf("ExtractTextFromFile() error = %v", err)
+		}
+		if result.CacheHit != wantHit {
+			t.Errorf("ExtractTextFromFile() %d cache hit = %v, want %v", i, result.CacheHit, wantHit)
+		}
+		if result.GetFullText() != "on disk" {
+			t.Errorf("ExtractTextFromFile() %d text = %q, want %q", i, result.GetFullText(), "on disk")
+		}
+	}
+}
+
+func TestDiskCache_Expired(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := pdfclient.NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	ctx := context.Background()
+	err = cache.Set(ctx, "key", &pdfclient.CacheEntry{
+		Response: &pdfclient.TextExtractionResponse{},
+		Expires:  time.Now().Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	entry, err := cache.Get(ctx, "key")
+	if err != nil || entry != nil {
+		t.Errorf("Get() = %v, %v, want a miss", entry, err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 0 {
+		t.Errorf("cache directory has %d files, want the expired entry removed", len(files))
+	}
+}
@@ -0,0 +1,125 @@
+package pdfclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// CallOption configures a single API call, overriding the Client defaults.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	method       string
+	outputFormat string
+	pages        string
+	timeout      time.Duration
+	header       http.Header
+	apiKey       *string
+}
+
+// WithMethod selects the extraction method: "auto", "pypdf2" or
+// "pdfplumber". For ExtractTextFromGCS it overrides GCSExtractionRequest.Method.
+func WithMethod(method string) CallOption {
+	return func(o *callOptions) {
+		o.method = method
+	}
+}
+
+// WithOutputFormat selects the output format of the extraction. For
+// ExtractTextFromGCS it overrides GCSExtractionRequest.OutputFormat.
+func WithOutputFormat(format string) CallOption {
+	return func(o *callOptions) {
+		o.outputFormat = format
+	}
+}
+
+// WithPages extracts only the given pages, a comma-separated list of pages
+// and ranges numbered from 1, such as "1-3,10,20-". A range with no end runs
+// to the end of the document. The pages keep their numbers in the document,
+// and PageCount reports the number of pages in the whole document.
+//
+// The range is sent to the service if Capabilities reports that it
+// supports page ranges. Otherwise uploads are trimmed locally to a PDF
+// holding only the selected pages, or, for encrypted files and
+// ExtractTextFromGCS, the whole document is extracted and the other pages
+// are dropped from the result.
+func WithPages(pages string) CallOption {
+	return func(o *callOptions) {
+		o.pages = pages
+	}
+}
+
+// WithCallTimeout overrides the Client timeout for each attempt of the call.
+func WithCallTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithHeader adds a header to the request. It may be given more than once.
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = http.Header{}
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithCallAPIKey overrides the Client API key for the call. An empty key
+// sends the request without one.
+func WithCallAPIKey(apiKey string) CallOption {
+	return func(o *callOptions) {
+		o.apiKey = &apiKey
+	}
+}
+
+func newCallOptions(options []CallOption) callOptions {
+	var o callOptions
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
+
+// formFields returns the multipart form fields sent with an upload.
+func (o callOptions) formFields() [][2]string {
+	var fields [][2]string
+	if o.method != "" {
+		fields = append(fields, [2]string{"method", o.method})
+	}
+	if o.outputFormat != "" {
+		fields = append(fields, [2]string{"output_format", o.outputFormat})
+	}
+	if o.pages != "" {
+		fields = append(fields, [2]string{"pages", o.pages})
+	}
+	return fields
+}
+
+// httpClient returns the HTTP client to use for the call.
+func (o callOptions) httpClient(c *Client) *http.Client {
+	if o.timeout <= 0 {
+		return c.HTTPClient
+	}
+	client := *c.HTTPClient
+	client.Timeout = o.timeout
+	return &client
+}
+
+// setHeaders applies the call's API key and extra headers to req.
+func (o callOptions) setHeaders(c *Client, req *http.Request) {
+	apiKey := c.APIKey
+	if o.apiKey != nil {
+		apiKey = *o.apiKey
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	for key, values := range o.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+}
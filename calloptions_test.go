@@ -0,0 +1,105 @@
+package pdfclient_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+const testPDF = "%PDF-1.7\n1 0 obj\n<<>>\nendobj\ntrailer\n<<>>\n%%EOF\n"
+
+func TestCallOptions_Upload(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithAPIKey("per-call"))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithAPIKey("client-key"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf",
+		pdfclient.WithMethod("pdfplumber"),
+		pdfclient.WithOutputFormat("json"),
+		pdfclient.WithHeader("X-Request-Id", "abc123"),
+		pdfclient.WithCallAPIKey("per-call"),
+	)
+	if err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	request, _ := server.LastRequest()
+	if request.Form.Get("method") != "pdfplumber" || request.Form.Get("output_format") != "json" {
+		t.Errorf("recorded form = %v, want method and output_format fields", request.Form)
+	}
+
+	if request.Header.Get("X-Request-Id") != "abc123" {
+		t.Errorf("recorded X-Request-Id = %q, want %q", request.Header.Get("X-Request-Id"), "abc123")
+	}
+
+	if request.Header.Get("X-API-Key") != "per-call" {
+		t.Errorf("recorded X-API-Key = %q, want %q", request.Header.Get("X-API-Key"), "per-call")
+	}
+
+	// Without the override the client key is rejected.
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+	if !errors.Is(err, pdfclient.ErrUnauthorized) {
+		t.Errorf("ExtractTextFromBytes() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestCallOptions_GCS(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithGCSObject("gs://bucket/test.pdf", "text"))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	result, err := client.ExtractTextFromGCS(context.Background(),
+		pdfclient.GCSExtractionRequest{InputGCSURL: "gs://bucket/test.pdf", Method: "auto"},
+		pdfclient.WithMethod("pypdf2"),
+		pdfclient.WithOutputFormat("json"),
+	)
+	if err != nil {
+		t.Fatalf("ExtractTextFromGCS() error = %v", err)
+	}
+
+	if result.Method != "pypdf2" {
+		t.Errorf("ExtractTextFromGCS() method = %v, want %v", result.Method, "pypdf2")
+	}
+
+	request, _ := server.LastRequest()
+	var body map[string]any
+	if err := json.Unmarshal(request.Body, &body); err != nil {
+		t.Fatalf("recorded body %q is not JSON: %v", request.Body, err)
+	}
+
+	if body["method"] != "pypdf2" || body["output_format"] != "json" {
+		t.Errorf("recorded body = %v, want call options to override the request", body)
+	}
+}
+
+func TestCallOptions_Timeout(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.Latency(200 * time.Millisecond)))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.HealthCheck(context.Background(), pdfclient.WithCallTimeout(20*time.Millisecond))
+	if !errors.Is(err, pdfclient.ErrTimeout) {
+		t.Errorf("HealthCheck() error = %v, want ErrTimeout", err)
+	}
+
+	if client.HTTPClient.Timeout != time.Minute {
+		t.Errorf("client timeout = %v, want it left at %v", client.HTTPClient.Timeout, time.Minute)
+	}
+}
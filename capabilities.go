@@ -0,0 +1,321 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Capabilities describes what the service supports.
+type Capabilities struct {
+	Version string
+	// Methods and OutputFormats are the accepted values of the method and
+	// output_format call options, or nil if the service does not say.
+	Methods       []string
+	OutputFormats []string
+	// GCS reports whether the service can extract from Google Cloud
+	// Storage. It is true unless the service reports otherwise.
+	GCS bool
+	// MaxFileSize is the largest upload the service accepts in bytes, or 0
+	// if it does not say.
+	MaxFileSize int64
+	// PageRanges reports whether the service accepts a pages field
+	// restricting extraction to a page range. It is false unless the
+	// service says it does.
+	PageRanges bool
+}
+
+// SupportsMethod reports whether method is accepted by the service, or
+// whether the accepted methods are unknown.
+func (c *Capabilities) SupportsMethod(method string) bool {
+	return c.Methods == nil || slices.Contains(c.Methods, method)
+}
+
+// SupportsOutputFormat reports whether format is accepted by the service, or
+// whether the accepted formats are unknown.
+func (c *Capabilities) SupportsOutputFormat(format string) bool {
+	return c.OutputFormats == nil || slices.Contains(c.OutputFormats, format)
+}
+
+// Capabilities returns what the service supports, discovering it on first
+// use and caching the result. The version and any capabilities reported by
+// the health check are used; the rest are derived from the service's
+// OpenAPI schema at /openapi.json if it is served. Call it at startup to
+// fail early when the service lacks a feature the caller needs.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	var capabilities Capabilities
+	known := func() bool {
+		if c.capabilities == nil {
+			return false
+		}
+		capabilities = *c.capabilities
+		capabilities.Methods = slices.Clone(c.capabilities.Methods)
+		capabilities.OutputFormats = slices.Clone(c.capabilities.OutputFormats)
+		return true
+	}
+	err := c.lookUp(ctx, &c.capabilitiesLookup, 0, known, func(ctx context.Context) error {
+		discovered, err := c.discoverCapabilities(ctx)
+		if err != nil {
+			return err
+		}
+		c.versionMu.Lock()
+		defer c.versionMu.Unlock()
+		c.capabilities = discovered
+		c.version = discovered.Version
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &capabilities, nil
+}
+
+// versionRetryInterval is how long a failed version lookup, or one that found
+// no version, is remembered before the service is asked again.
+const versionRetryInterval = 30 * time.Second
+
+// discoverVersion returns the version reported by the service, checking its
+// health on first use. It returns "" if the service reports no version.
+func (c *Client) discoverVersion(ctx context.Context) (string, error) {
+	var version string
+	known := func() bool {
+		version = c.version
+		return version != ""
+	}
+	err := c.lookUp(ctx, &c.versionLookup, versionRetryInterval, known, func(ctx context.Context) error {
+		health, err := c.HealthCheck(ctx)
+		if err != nil {
+			return err
+		}
+		c.setVersion(health.Version)
+		return nil
+	})
+	return version, err
+}
+
+// setVersion records a version reported by the service, forgetting the
+// capabilities if it changed.
+func (c *Client) setVersion(version string) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+
+	if version != c.version {
+		c.version = version
+		c.capabilities = nil
+	}
+}
+
+// lookup is a request for the version or capabilities of the service that
+// concurrent callers share.
+type lookup struct {
+	done     chan struct{} // closed when the lookup finishes
+	err      error
+	finished time.Time
+}
+
+// lookUp discovers a value with fetch unless known, which is called with
+// versionMu held, reports that it is already known. Concurrent callers share
+// one call of fetch, which runs without versionMu held and stores the value
+// itself. A lookup that fails or finds nothing is remembered for retryAfter,
+// so a failing service is not asked on every call; one that failed because
+// its caller gave up is forgotten.
+func (c *Client) lookUp(ctx context.Context, slot **lookup, retryAfter time.Duration, known func() bool, fetch func(context.Context) error) error {
+	for {
+		c.versionMu.Lock()
+		if known() {
+			c.versionMu.Unlock()
+			return nil
+		}
+
+		l := *slot
+		if l != nil {
+			select {
+			case <-l.done:
+				if time.Since(l.finished) < retryAfter {
+					c.versionMu.Unlock()
+					return l.err
+				}
+				l = nil
+			default:
+			}
+		}
+
+		if l == nil {
+			l = &lookup{done: make(chan struct{})}
+			*slot = l
+			c.versionMu.Unlock()
+
+			err := fetch(ctx)
+
+			c.versionMu.Lock()
+			l.err, l.finished = err, time.Now()
+			if err != nil && ctx.Err() != nil && *slot == l {
+				*slot = nil
+			}
+			close(l.done)
+			c.versionMu.Unlock()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		c.versionMu.Unlock()
+
+		select {
+		case <-l.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if l.err != nil {
+			c.versionMu.Lock()
+			current := *slot == l
+			c.versionMu.Unlock()
+			if current {
+				return l.err
+			}
+		}
+	}
+}
+
+// serviceHealth is a health response with the optional capability fields.
+type serviceHealth struct {
+	HealthResponse
+	Methods       []string `json:"methods"`
+	OutputFormats []string `json:"output_formats"`
+	GCSEnabled    *bool    `json:"gcs_enabled"`
+	MaxFileSize   int64    `json:"max_file_size"`
+	PageRanges    *bool    `json:"page_ranges"`
+}
+
+func (c *Client) discoverCapabilities(ctx context.Context) (*Capabilities, error) {
+	var health serviceHealth
+	err := c.do(ctx, apiRequest{
+		op:     OpHealthCheck,
+		method: http.MethodGet,
+		path:   "/health",
+	}, &health)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := &Capabilities{
+		Version:       health.Version,
+		Methods:       health.Methods,
+		OutputFormats: health.OutputFormats,
+		GCS:           health.GCSEnabled == nil || *health.GCSEnabled,
+		MaxFileSize:   health.MaxFileSize,
+		PageRanges:    health.PageRanges != nil && *health.PageRanges,
+	}
+	if health.Methods != nil && health.OutputFormats != nil && health.GCSEnabled != nil {
+		return capabilities, nil
+	}
+
+	var doc openAPIDocument
+	err = c.do(ctx, apiRequest{
+		op:     OpCapabilities,
+		method: http.MethodGet,
+		path:   "/openapi.json",
+	}, &doc)
+	if err != nil {
+		// A service without a schema, such as one with the docs disabled,
+		// just leaves the rest unknown.
+		var clientErr *ClientError
+		if !errors.As(err, &clientErr) {
+			return nil, err
+		}
+		c.logger().LogAttrs(ctx, slog.LevelDebug, "pdfclient OpenAPI schema unavailable",
+			slog.Any("error", err))
+		return capabilities, nil
+	}
+
+	form := doc.requestSchema("/extract", "multipart/form-data")
+	if capabilities.Methods == nil {
+		capabilities.Methods = doc.enum(form.property("method"))
+	}
+	if capabilities.OutputFormats == nil {
+		capabilities.OutputFormats = doc.enum(form.property("output_format"))
+	}
+	if health.GCSEnabled == nil {
+		_, capabilities.GCS = doc.Paths["/extract-from-gcs"]
+	}
+	if health.PageRanges == nil {
+		capabilities.PageRanges = form.property("pages") != nil
+	}
+	return capabilities, nil
+}
+
+// openAPIDocument is the part of an OpenAPI schema used to discover
+// capabilities.
+type openAPIDocument struct {
+	Paths map[string]map[string]struct {
+		RequestBody struct {
+			Content map[string]struct {
+				Schema *openAPISchema `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+	} `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Enum       []any                     `json:"enum"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	AllOf      []*openAPISchema          `json:"allOf"`
+	AnyOf      []*openAPISchema          `json:"anyOf"`
+	OneOf      []*openAPISchema          `json:"oneOf"`
+}
+
+func (s *openAPISchema) property(name string) *openAPISchema {
+	if s == nil {
+		return nil
+	}
+	return s.Properties[name]
+}
+
+// requestSchema returns the schema of the body of a POST to path with the
+// given content type, or nil if there is none.
+func (d *openAPIDocument) requestSchema(path, contentType string) *openAPISchema {
+	content, ok := d.Paths[path]["post"].RequestBody.Content[contentType]
+	if !ok {
+		return nil
+	}
+	return d.resolve(content.Schema)
+}
+
+// resolve follows $ref to a component schema.
+func (d *openAPIDocument) resolve(s *openAPISchema) *openAPISchema {
+	for depth := 0; s != nil && s.Ref != "" && depth < 8; depth++ {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// enum returns the string values allowed by s, looking through references
+// and the combinations FastAPI uses for optional and defaulted fields. It
+// returns nil if s does not restrict the values.
+func (d *openAPIDocument) enum(s *openAPISchema) []string {
+	s = d.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	var values []string
+	for _, v := range s.Enum {
+		if str, ok := v.(string); ok {
+			values = append(values, str)
+		}
+	}
+	for _, subs := range [][]*openAPISchema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, sub := range subs {
+			values = append(values, d.enum(sub)...)
+		}
+	}
+	return values
+}
@@ -0,0 +1,126 @@
+package pdfclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestCapabilities_FromOpenAPI(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithVersion("1.3.0"))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	capabilities, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+
+	if capabilities.Version != "1.3.0" {
+		t.Errorf("Capabilities() version = %v, want %v", capabilities.Version, "1.3.0")
+	}
+	if want := []string{"auto", "pypdf2", "pdfplumber"}; !slices.Equal(capabilities.Methods, want) {
+		t.Errorf("Capabilities() methods = %v, want %v", capabilities.Methods, want)
+	}
+	if want := []string{"text", "json"}; !slices.Equal(capabilities.OutputFormats, want) {
+		t.Errorf("Capabilities() output formats = %v, want %v", capabilities.OutputFormats, want)
+	}
+	if !capabilities.GCS {
+		t.Errorf("Capabilities() GCS = false, want true")
+	}
+	if capabilities.PageRanges {
+		t.Errorf("Capabilities() PageRanges = true, want false")
+	}
+	if capabilities.SupportsMethod("ocr") {
+		t.Errorf("SupportsMethod(%q) = true, want false", "ocr")
+	}
+
+	// The result is cached.
+	if _, err := client.Capabilities(context.Background()); err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	if n := len(server.Requests()); n != 2 {
+		t.Errorf("server received %d requests, want %d", n, 2)
+	}
+}
+
+func TestCapabilities_FromHealth(t *testing.T) {
+	var openAPIRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/health":
+			w.Write([]byte(`{"status":"ok","version":"2.0.0","methods":["pdfplumber"],"output_formats":["text"],"gcs_enabled":false,"max_file_size":1048576}`))
+		case "/openapi.json":
+			openAPIRequests++
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail":"Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	capabilities, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+
+	want := pdfclient.Capabilities{
+		Version:       "2.0.0",
+		Methods:       []string{"pdfplumber"},
+		OutputFormats: []string{"text"},
+		GCS:           false,
+		MaxFileSize:   1 << 20,
+	}
+	if capabilities.Version != want.Version || !slices.Equal(capabilities.Methods, want.Methods) ||
+		!slices.Equal(capabilities.OutputFormats, want.OutputFormats) ||
+		capabilities.GCS != want.GCS || capabilities.MaxFileSize != want.MaxFileSize {
+		t.Errorf("Capabilities() = %+v, want %+v", *capabilities, want)
+	}
+	if openAPIRequests != 0 {
+		t.Errorf("server received %d OpenAPI requests, want %d", openAPIRequests, 0)
+	}
+}
+
+func TestCapabilities_WithoutOpenAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/health" {
+			w.Write([]byte(`{"status":"ok","version":"1.0.0"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail":"Not Found"}`))
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	capabilities, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+
+	if capabilities.Methods != nil || capabilities.OutputFormats != nil {
+		t.Errorf("Capabilities() = %+v, want unknown methods and output formats", *capabilities)
+	}
+	if !capabilities.GCS || !capabilities.SupportsMethod("pypdf2") {
+		t.Errorf("Capabilities() = %+v, want unknown capabilities to be assumed supported", *capabilities)
+	}
+}
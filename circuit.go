@@ -0,0 +1,231 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the service while the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets calls through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails calls with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen fails calls while a health check probes the service.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreaker configures a circuit breaker. Calls fail when the service
+// cannot be reached, times out, or responds with 429 or a 5xx status; other
+// errors, such as an invalid PDF, show that the service is working.
+type CircuitBreaker struct {
+	// ConsecutiveFailures opens the circuit after this many failed calls in
+	// a row. It defaults to 5 when FailureRatio is not set.
+	ConsecutiveFailures int
+	// FailureRatio opens the circuit when at least this fraction of the
+	// last Window calls failed. Zero disables it.
+	FailureRatio float64
+	// Window is the number of recent calls considered by FailureRatio. It
+	// defaults to 20.
+	Window int
+	// CoolDown is how long the circuit stays open before the next call
+	// probes the service with HealthCheck. It defaults to 30 seconds.
+	CoolDown time.Duration
+	// OnStateChange, if set, is called after every state transition.
+	OnStateChange func(from, to CircuitState)
+}
+
+// WithCircuitBreaker makes the client fail fast with ErrCircuitOpen while the
+// service is failing. Once the cool-down has passed, the next call probes the
+// service with HealthCheck and closes the circuit if it succeeds. Health
+// checks are never blocked by the breaker and do not count towards it.
+func WithCircuitBreaker(config CircuitBreaker) ClientOption {
+	return func(c *Client) {
+		c.breaker = newBreaker(config)
+	}
+}
+
+// CircuitState returns the state of the client's circuit breaker, or
+// CircuitClosed if it has none.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	c.breaker.mu.Lock()
+	defer c.breaker.mu.Unlock()
+	return c.breaker.state
+}
+
+type breaker struct {
+	config CircuitBreaker
+
+	mu          sync.Mutex
+	state       CircuitState
+	openedAt    time.Time
+	consecutive int
+	outcomes    []bool // ring of recent calls, true for failures
+	next        int
+	failures    int
+}
+
+func newBreaker(config CircuitBreaker) *breaker {
+	if config.ConsecutiveFailures <= 0 && config.FailureRatio <= 0 {
+		config.ConsecutiveFailures = 5
+	}
+	if config.Window <= 0 {
+		config.Window = 20
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+	return &breaker{config: config}
+}
+
+// allow reports whether a call may proceed, probing the service if the
+// cool-down has passed.
+func (c *Client) allow(ctx context.Context) error {
+	b := c.breaker
+
+	b.mu.Lock()
+	if b.state == CircuitClosed {
+		b.mu.Unlock()
+		return nil
+	}
+	if b.state == CircuitHalfOpen || time.Since(b.openedAt) < b.config.CoolDown {
+		b.mu.Unlock()
+		return ErrCircuitOpen
+	}
+	from := b.transition(CircuitHalfOpen)
+	b.mu.Unlock()
+	c.circuitChanged(ctx, from, CircuitHalfOpen)
+
+	_, err := c.HealthCheck(ctx)
+
+	b.mu.Lock()
+	switch {
+	case err == nil:
+		b.reset()
+		from = b.transition(CircuitClosed)
+	case ctx.Err() != nil:
+		// The caller gave up; let the next call probe again.
+		from = b.transition(CircuitOpen)
+	default:
+		from = b.transition(CircuitOpen)
+		b.openedAt = time.Now()
+	}
+	to := b.state
+	b.mu.Unlock()
+	c.circuitChanged(ctx, from, to)
+
+	if err != nil {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record counts the outcome of a call allowed through a closed circuit.
+func (c *Client) record(ctx context.Context, err error) {
+	b := c.breaker
+	failed := isServiceFailure(err)
+
+	b.mu.Lock()
+	if b.state != CircuitClosed {
+		b.mu.Unlock()
+		return
+	}
+
+	if failed {
+		b.consecutive++
+	} else {
+		b.consecutive = 0
+	}
+
+	if len(b.outcomes) < b.config.Window {
+		b.outcomes = append(b.outcomes, failed)
+	} else {
+		if b.outcomes[b.next] {
+			b.failures--
+		}
+		b.outcomes[b.next] = failed
+		b.next = (b.next + 1) % b.config.Window
+	}
+	if failed {
+		b.failures++
+	}
+
+	trip := b.config.ConsecutiveFailures > 0 && b.consecutive >= b.config.ConsecutiveFailures
+	if b.config.FailureRatio > 0 && len(b.outcomes) == b.config.Window &&
+		float64(b.failures)/float64(b.config.Window) >= b.config.FailureRatio {
+		trip = true
+	}
+	if !trip {
+		b.mu.Unlock()
+		return
+	}
+
+	from := b.transition(CircuitOpen)
+	b.openedAt = time.Now()
+	b.mu.Unlock()
+	c.circuitChanged(ctx, from, CircuitOpen)
+}
+
+// transition sets the state and returns the previous one. b.mu must be held.
+func (b *breaker) transition(to CircuitState) CircuitState {
+	from := b.state
+	b.state = to
+	return from
+}
+
+// reset clears the recorded outcomes. b.mu must be held.
+func (b *breaker) reset() {
+	b.consecutive = 0
+	b.outcomes = b.outcomes[:0]
+	b.next = 0
+	b.failures = 0
+}
+
+func (c *Client) circuitChanged(ctx context.Context, from, to CircuitState) {
+	if from == to {
+		return
+	}
+	c.logger().LogAttrs(ctx, slog.LevelDebug, "pdfclient circuit breaker state changed",
+		slog.String("from", from.String()),
+		slog.String("to", to.String()))
+	if c.breaker.config.OnStateChange != nil {
+		c.breaker.config.OnStateChange(from, to)
+	}
+}
+
+// isServiceFailure reports whether err shows that the service is unhealthy.
+func isServiceFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.StatusCode == http.StatusTooManyRequests || clientErr.StatusCode >= 500
+	}
+	return errors.Is(err, ErrNetwork) || errors.Is(err, ErrTimeout)
+}
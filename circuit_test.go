@@ -0,0 +1,175 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+type transitions struct {
+	mu  sync.Mutex
+	got []string
+}
+
+func (tr *transitions) record(from, to pdfclient.CircuitState) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.got = append(tr.got, fmt.Sprintf("%v->%v", from, to))
+}
+
+func (tr *transitions) check(t *testing.T, want ...string) {
+	t.Helper()
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if fmt.Sprint(tr.got) != fmt.Sprint(want) {
+		t.Errorf("state transitions = %v, want %v", tr.got, want)
+	}
+}
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.ServerError(503), pdfclienttest.OnPath("/extract")),
+	)
+	defer server.Close()
+
+	tr := &transitions{}
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithCircuitBreaker(pdfclient.CircuitBreaker{
+		ConsecutiveFailures: 3,
+		CoolDown:            50 * time.Millisecond,
+		OnStateChange:       tr.record,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+		if !errors.Is(err, pdfclient.ErrServerUnavailable) {
+			t.Fatalf("ExtractTextFromBytes() error = %v, want ErrServerUnavailable", err)
+		}
+	}
+
+	if client.CircuitState() != pdfclient.CircuitOpen {
+		t.Fatalf("CircuitState() = %v, want %v", client.CircuitState(), pdfclient.CircuitOpen)
+	}
+
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+	if !errors.Is(err, pdfclient.ErrCircuitOpen) {
+		t.Errorf("ExtractTextFromBytes() error = %v, want ErrCircuitOpen", err)
+	}
+	if n := len(server.Requests()); n != 3 {
+		t.Errorf("server received %d requests, want %d", n, 3)
+	}
+
+	// Health checks are not blocked by the breaker.
+	if _, err := client.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v", err)
+	}
+
+	// After the cool-down a successful probe closes the circuit.
+	server.ClearFaults()
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+	if client.CircuitState() != pdfclient.CircuitClosed {
+		t.Errorf("CircuitState() = %v, want %v", client.CircuitState(), pdfclient.CircuitClosed)
+	}
+
+	requests := server.Requests()
+	if probe := requests[len(requests)-2]; probe.Path != "/health" {
+		t.Errorf("probe request path = %v, want /health", probe.Path)
+	}
+
+	tr.check(t, "closed->open", "open->half-open", "half-open->closed")
+}
+
+func TestCircuitBreaker_FailedProbe(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.ServerError(502)))
+	defer server.Close()
+
+	tr := &transitions{}
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithCircuitBreaker(pdfclient.CircuitBreaker{
+		ConsecutiveFailures: 1,
+		CoolDown:            20 * time.Millisecond,
+		OnStateChange:       tr.record,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+	time.Sleep(30 * time.Millisecond)
+
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+	if !errors.Is(err, pdfclient.ErrCircuitOpen) {
+		t.Errorf("ExtractTextFromBytes() error = %v, want ErrCircuitOpen", err)
+	}
+
+	// The failed probe starts a new cool-down.
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+	if !errors.Is(err, pdfclient.ErrCircuitOpen) {
+		t.Errorf("ExtractTextFromBytes() error = %v, want ErrCircuitOpen", err)
+	}
+	if n := len(server.Requests()); n != 2 {
+		t.Errorf("server received %d requests, want the failed call and one probe", n)
+	}
+
+	tr.check(t, "closed->open", "open->half-open", "half-open->open")
+}
+
+func TestCircuitBreaker_FailureRatio(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.ServerError(500), pdfclienttest.OnRequests(1, 3)),
+	)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithCircuitBreaker(pdfclient.CircuitBreaker{
+		FailureRatio: 0.5,
+		Window:       4,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if client.CircuitState() != pdfclient.CircuitClosed {
+			t.Fatalf("CircuitState() after %d calls = %v, want %v", i, client.CircuitState(), pdfclient.CircuitClosed)
+		}
+		client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+	}
+
+	if client.CircuitState() != pdfclient.CircuitOpen {
+		t.Errorf("CircuitState() = %v, want %v", client.CircuitState(), pdfclient.CircuitOpen)
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithCircuitBreaker(pdfclient.CircuitBreaker{
+		ConsecutiveFailures: 2,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.ExtractTextFromBytes(context.Background(), []byte("not a pdf"), "test.pdf")
+		if !errors.Is(err, pdfclient.ErrInvalidPDF) {
+			t.Fatalf("ExtractTextFromBytes() error = %v, want ErrInvalidPDF", err)
+		}
+	}
+
+	if client.CircuitState() != pdfclient.CircuitClosed {
+		t.Errorf("CircuitState() = %v, want %v", client.CircuitState(), pdfclient.CircuitClosed)
+	}
+}
@@ -4,15 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"mime/multipart"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,6 +24,60 @@ type Client struct {
 	Debug      bool
 	Timeout    time.Duration
 	APIKey     string
+
+	// Logger receives structured request events. If nil, debug mode logs to
+	// stderr and other events go to the slog default logger.
+	Logger *slog.Logger
+	// LogBodyLimit is the number of bytes of request and response bodies
+	// included in debug log events. Zero disables body logging.
+	LogBodyLimit int
+
+	// RetryPolicy controls retries of failed requests. The zero value makes
+	// a single attempt.
+	RetryPolicy RetryPolicy
+
+	// RequestLimiter, if set, paces requests, taking one token per attempt.
+	RequestLimiter Limiter
+	// UploadLimiter, if set, paces request bodies, taking one token per byte.
+	UploadLimiter Limiter
+
+	// Observers receive lifecycle events for every API call.
+	Observers []Observer
+
+	stats         *StatsRecorder
+	concurrency   *semaphore
+	inFlightBytes *semaphore
+	adaptive      *adaptiveLimiter
+	breaker       *breaker
+
+	cache        Cache
+	cacheOptions CacheOptions
+
+	versionMu          sync.Mutex // guards the fields below, not the lookups
+	version            string     // discovered server version
+	capabilities       *Capabilities
+	versionLookup      *lookup
+	capabilitiesLookup *lookup
+
+	versionConstraint string
+	constraint        *versionConstraint
+
+	flights      *flightGroup
+	preflight    *PreflightOptions
+	spoolOptions SpoolOptions
+
+	endpointURLs    []string
+	endpointOptions EndpointOptions
+	balancer        *balancer
+
+	monitor *healthMonitor
+
+	// closeCtx is cancelled by Close to stop the background goroutines,
+	// which are tracked by background.
+	closeCtx     context.Context
+	closeClient  context.CancelFunc
+	backgroundMu sync.Mutex
+	background   sync.WaitGroup
 }
 
 type ClientOption func(*Client)
@@ -58,43 +113,108 @@ func WithAPIKey(apiKey string) ClientOption {
 }
 
 func NewClient(baseURL string, options ...ClientOption) (*Client, error) {
-	if !strings.Contains(baseURL, "://") {
-		baseURL = "http://" + baseURL
-	}
-
-	parsedURL, err := url.Parse(baseURL)
+	baseURL, err := normalizeBaseURL(baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid base URL: %w", err)
+		return nil, err
 	}
 
-	parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
-
 	client := &Client{
-		BaseURL: parsedURL.String(),
+		BaseURL: baseURL,
 		HTTPClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
 		UserAgent: "Go-PyPDFToTextClient/1.0",
 		Debug:     false,
 		Timeout:   120 * time.Second,
+		stats:     NewStatsRecorder(),
 	}
+	client.closeCtx, client.closeClient = context.WithCancel(context.Background())
 
 	for _, option := range options {
 		option(client)
 	}
 
+	if len(client.endpointURLs) > 0 {
+		urls := make([]string, len(client.endpointURLs))
+		for i, endpointURL := range client.endpointURLs {
+			if urls[i], err = normalizeBaseURL(endpointURL); err != nil {
+				return nil, err
+			}
+		}
+		client.BaseURL = urls[0]
+		client.balancer = newBalancer(urls, client.endpointOptions)
+	}
+
+	if client.versionConstraint != "" {
+		if client.constraint, err = parseVersionConstraint(client.versionConstraint); err != nil {
+			return nil, err
+		}
+	}
+
 	// Apply the timeout to the HTTP client if it wasn't provided via WithHTTPClient
 	// Check if the HTTPClient timeout differs from our Timeout setting
 	if client.HTTPClient.Timeout != client.Timeout {
 		client.HTTPClient.Timeout = client.Timeout
 	}
 
+	if client.monitor != nil {
+		client.goBackground(client.monitorHealth)
+	}
+
 	return client, nil
 }
 
+func normalizeBaseURL(baseURL string) (string, error) {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
+	return parsedURL.String(), nil
+}
+
+// Close stops the client's background work, such as the health monitor and
+// health checks of ejected endpoints, and waits for it to finish. The client
+// can still be used for requests.
+func (c *Client) Close() error {
+	if c.closeClient == nil {
+		return nil
+	}
+	c.backgroundMu.Lock()
+	c.closeClient()
+	c.backgroundMu.Unlock()
+
+	c.background.Wait()
+	return nil
+}
+
+// goBackground runs fn in a goroutine that Close cancels and waits for. It
+// does nothing once the client is closed.
+func (c *Client) goBackground(fn func(ctx context.Context)) {
+	c.backgroundMu.Lock()
+	defer c.backgroundMu.Unlock()
+	if c.closeCtx == nil || c.closeCtx.Err() != nil {
+		return
+	}
+
+	c.background.Add(1)
+	go func() {
+		defer c.background.Done()
+		fn(c.closeCtx)
+	}()
+}
+
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Version string `json:"version"`
+
+	// Endpoint is the base URL of the service that answered.
+	Endpoint string `json:"-"`
 }
 
 type PageData struct {
@@ -107,6 +227,13 @@ type TextExtractionResponse struct {
 	PageCount int        `json:"page_count"`
 	FileName  string     `json:"file_name"`
 	FileSize  int        `json:"file_size"`
+
+	// CacheHit reports whether the response was served from the cache set
+	// with WithCache.
+	CacheHit bool `json:"-"`
+	// Endpoint is the base URL of the service that answered, or "" for a
+	// cache hit.
+	Endpoint string `json:"-"`
 }
 
 // GetFullText reconstructs the full text from pages
@@ -127,6 +254,9 @@ type GCSExtractionRequest struct {
 	Method       string  `json:"method,omitempty"`
 	ProjectID    *string `json:"project_id,omitempty"`
 	OutputFormat string  `json:"output_format,omitempty"`
+	// Pages restricts the extraction to a page range, such as "1-3,10,20-".
+	// See WithPages.
+	Pages string `json:"pages,omitempty"`
 }
 
 type GCSExtractionResponse struct {
@@ -136,6 +266,9 @@ type GCSExtractionResponse struct {
 	FileSize       int        `json:"file_size"`
 	Method         string     `json:"method"`
 	OutputLocation *string    `json:"output_location,omitempty"`
+
+	// Endpoint is the base URL of the service that answered.
+	Endpoint string `json:"-"`
 }
 
 // GetFullText reconstructs the full text from pages
@@ -150,283 +283,487 @@ func (r *GCSExtractionResponse) GetFullText() string {
 	return sb.String()
 }
 
-type ClientError struct {
-	StatusCode int
-	Message    string
-	Detail     string
-}
-
-func (e ClientError) Error() string {
-	if e.Detail != "" {
-		return fmt.Sprintf("API error (HTTP %d): %s - %s", e.StatusCode, e.Message, e.Detail)
+func (c *Client) HealthCheck(ctx context.Context, opts ...CallOption) (*HealthResponse, error) {
+	var health HealthResponse
+	err := c.do(ctx, apiRequest{
+		op:     OpHealthCheck,
+		method: http.MethodGet,
+		path:   "/health",
+		opts:   newCallOptions(opts),
+	}, &health)
+	if err != nil {
+		return nil, err
 	}
-	return fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Message)
-}
 
-// IsInvalidPDFError returns true if the error is related to an invalid PDF
-func (e ClientError) IsInvalidPDFError() bool {
-	return e.StatusCode == http.StatusBadRequest && 
-		(strings.Contains(e.Detail, "PDF file has syntax errors") || 
-		 strings.Contains(e.Detail, "PDF file appears to be corrupted") || 
-		 strings.Contains(e.Detail, "PDF file is incomplete or truncated") ||
-		 strings.Contains(e.Detail, "Invalid PDF format") ||
-		 strings.Contains(e.Detail, "file does not appear to be a valid PDF"))
-}
-
-// IsTimeoutError returns true if the error is a timeout error
-func (e ClientError) IsTimeoutError() bool {
-	return e.StatusCode == http.StatusRequestTimeout || 
-		strings.Contains(e.Detail, "timed out")
+	return &health, nil
 }
 
-// IsFileSizeError returns true if the error is related to a file size limit
-func (e ClientError) IsFileSizeError() bool {
-	return e.StatusCode == http.StatusRequestEntityTooLarge ||
-		strings.Contains(e.Detail, "File too large")
-}
+func (c *Client) ExtractTextFromFile(ctx context.Context, filePath string, opts ...CallOption) (*TextExtractionResponse, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer func(file *os.File) {
+		err = file.Close()
+		if err != nil {
+			c.logger().Error("Failed to close file", "error", err)
+		}
+	}(file)
 
-// IsGCSPermissionError returns true if the error is related to GCS permissions
-func (e ClientError) IsGCSPermissionError() bool {
-	return e.StatusCode == http.StatusForbidden &&
-		strings.Contains(e.Detail, "Permission denied")
+	return c.ExtractTextFromReader(ctx, file, filepath.Base(filePath), opts...)
 }
 
-// IsGCSNotFoundError returns true if the error is related to GCS resource not found
-func (e ClientError) IsGCSNotFoundError() bool {
-	return e.StatusCode == http.StatusNotFound &&
-		(strings.Contains(e.Detail, "not found") || strings.Contains(e.Detail, "does not exist"))
+func (c *Client) ExtractTextFromBytes(ctx context.Context, fileContent []byte, fileName string, opts ...CallOption) (*TextExtractionResponse, error) {
+	reader := bytes.NewReader(fileContent)
+	return c.ExtractTextFromReader(ctx, reader, fileName, opts...)
 }
 
-func (c *Client) HealthCheck(ctx context.Context) (*HealthResponse, error) {
-	reqURL := fmt.Sprintf("%s/health", c.BaseURL)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+// ExtractTextFromReader uploads the contents of reader for extraction. The
+// upload is streamed; when reader is an *os.File, *bytes.Reader or another
+// reader with a known length the request carries a Content-Length,
+// otherwise it is sent with chunked transfer encoding. The method and output
+// format call options are sent as form fields; see WithPages for how page
+// ranges are extracted.
+//
+// Seekable readers are rewound for retries. Readers that are not seekable
+// are copied to memory or a temporary file, as configured with
+// WithSpooling: as the first attempt uploads them when retries are enabled,
+// or before the upload when the cache, deduplication, preflight validation
+// or WithPages needs the whole input.
+func (c *Client) ExtractTextFromReader(ctx context.Context, reader io.Reader, fileName string, opts ...CallOption) (*TextExtractionResponse, error) {
+	options := newCallOptions(opts)
+
+	if !seekable(reader) {
+		if c.needsContent(options) {
+			spooled, release, err := c.spool(reader)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			reader = spooled
+		} else if c.RetryPolicy.attempts() > 1 {
+			// Retries, including failover to another endpoint, replay
+			// what the earlier attempts read.
+			spool := c.newTeeSpool(reader)
+			defer spool.release()
+			reader = spool
+		}
 	}
 
-	if c.UserAgent != "" {
-		req.Header.Set("User-Agent", c.UserAgent)
+	if c.preflight != nil {
+		if err := c.preflightReader(reader, fileName); err != nil {
+			return nil, err
+		}
 	}
 
-	if c.APIKey != "" {
-		req.Header.Set("X-API-Key", c.APIKey)
+	if options.pages != "" {
+		ranges, err := parsePageRanges(options.pages)
+		if err != nil {
+			return nil, err
+		}
+		if !c.supportsPageRanges(ctx) {
+			return c.extractPages(ctx, reader, fileName, ranges, options)
+		}
+		options.pages = formatPageRanges(ranges)
 	}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Making request to %s\n", reqURL)
-	}
+	return c.extractFromReader(ctx, reader, fileName, options)
+}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err = Body.Close()
+// extractFromReader uploads reader, sharing the result with the cache and
+// concurrent identical calls when they are enabled.
+func (c *Client) extractFromReader(ctx context.Context, reader io.Reader, fileName string, options callOptions) (*TextExtractionResponse, error) {
+	start := time.Now()
+	var key string
+	var size int64
+	if c.cache != nil || c.flights != nil {
+		var err error
+		key, size, err = contentKey(reader, options)
 		if err != nil {
-			slog.Error("Failed to close response body in remote PyPDFToText health check", "error", err)
+			return nil, err
 		}
-	}(resp.Body)
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		bodyStr := string(body)
-		
-		// Try to parse the error response as JSON
-		var apiError struct {
-			Detail string `json:"detail"`
-		}
-		
-		detail := ""
-		if err := json.Unmarshal(body, &apiError); err == nil && apiError.Detail != "" {
-			detail = apiError.Detail
-		}
-		
-		return nil, ClientError{
-			StatusCode: resp.StatusCode,
-			Message:    bodyStr,
-			Detail:     detail,
+	if c.cache != nil && key != "" {
+		if result := c.cachedExtraction(ctx, key, fileName); result != nil {
+			c.observeCacheHit(ctx, start, result)
+			return result, nil
 		}
 	}
 
-	var health HealthResponse
-	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	if c.flights != nil && key != "" {
+		return c.flights.do(ctx, options.flightKey(key), reader.(io.ReadSeeker), size, fileName,
+			func(ctx context.Context, source io.Reader) (*TextExtractionResponse, error) {
+				return c.extract(ctx, source, fileName, options, key)
+			})
 	}
 
-	return &health, nil
+	return c.extract(ctx, reader, fileName, options, key)
 }
 
-func (c *Client) ExtractTextFromFile(ctx context.Context, filePath string) (*TextExtractionResponse, error) {
-	file, err := os.Open(filePath)
+// extract uploads reader and caches the result under key, if set.
+func (c *Client) extract(ctx context.Context, reader io.Reader, fileName string, options callOptions, key string) (*TextExtractionResponse, error) {
+	upload, err := newUploadBody(reader, fileName, options.formFields())
 	if err != nil {
-		return nil, fmt.Errorf("error opening file: %w", err)
+		return nil, err
 	}
-	defer func(file *os.File) {
-		err = file.Close()
-		if err != nil {
-			slog.Error("Failed to close file", "error", err)
-		}
-	}(file)
 
-	return c.ExtractTextFromReader(ctx, file, filepath.Base(filePath))
-}
+	var result TextExtractionResponse
+	err = c.do(ctx, apiRequest{
+		op:          OpExtract,
+		method:      http.MethodPost,
+		path:        "/extract",
+		fileName:    fileName,
+		contentType: upload.contentType,
+		body:        upload.open,
+		size:        upload.length(),
+		replayable:  upload.replayable(),
+		opts:        options,
+		attrs:       []slog.Attr{slog.String("file", fileName)},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
 
-func (c *Client) ExtractTextFromBytes(ctx context.Context, fileContent []byte, fileName string) (*TextExtractionResponse, error) {
-	reader := bytes.NewReader(fileContent)
-	return c.ExtractTextFromReader(ctx, reader, fileName)
+	if c.cache != nil && key != "" {
+		c.cacheExtraction(ctx, key, &result)
+	}
+
+	return &result, nil
 }
 
-func (c *Client) ExtractTextFromReader(ctx context.Context, reader io.Reader, fileName string) (*TextExtractionResponse, error) {
-	reqURL := fmt.Sprintf("%s/extract", c.BaseURL)
+func (c *Client) ExtractTextFromGCS(ctx context.Context, request GCSExtractionRequest, opts ...CallOption) (*GCSExtractionResponse, error) {
+	options := newCallOptions(opts)
+	if options.method != "" {
+		request.Method = options.method
+	}
+	if options.outputFormat != "" {
+		request.OutputFormat = options.outputFormat
+	}
+	if options.pages != "" {
+		request.Pages = options.pages
+	}
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	// Services that cannot select pages extract them all, and the others
+	// are dropped.
+	var ranges []pageRange
+	if request.Pages != "" {
+		var err error
+		ranges, err = parsePageRanges(request.Pages)
+		if err != nil {
+			return nil, err
+		}
+		request.Pages = formatPageRanges(ranges)
+		if c.supportsPageRanges(ctx) {
+			ranges = nil
+		} else {
+			request.Pages = ""
+		}
+	}
 
-	part, err := writer.CreateFormFile("file", fileName)
-	if err != nil {
-		return nil, fmt.Errorf("error creating form file: %w", err)
+	// Set default method if not provided
+	if request.Method == "" {
+		request.Method = "auto"
 	}
 
-	if _, err := io.Copy(part, reader); err != nil {
-		return nil, fmt.Errorf("error copying file data: %w", err)
+	// Set default output format if not provided
+	if request.OutputFormat == "" {
+		request.OutputFormat = "text"
 	}
 
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("error closing multipart writer: %w", err)
+	// Marshal request body
+	jsonBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, body)
+	var result GCSExtractionResponse
+	err = c.do(ctx, apiRequest{
+		op:          OpExtractGCS,
+		method:      http.MethodPost,
+		path:        "/extract-from-gcs",
+		fileName:    request.InputGCSURL,
+		contentType: "application/json",
+		body:        bytesBody(jsonBody),
+		size:        int64(len(jsonBody)),
+		replayable:  true,
+		opts:        options,
+		attrs:       []slog.Attr{slog.String("gcs_url", request.InputGCSURL)},
+	}, &result)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	if c.UserAgent != "" {
-		req.Header.Set("User-Agent", c.UserAgent)
+	if ranges != nil {
+		selected := make([]PageData, 0, len(result.Pages))
+		for _, page := range result.Pages {
+			if containsPage(ranges, page.Page) {
+				selected = append(selected, page)
+			}
+		}
+		result.Pages = selected
 	}
 
-	if c.APIKey != "" {
-		req.Header.Set("X-API-Key", c.APIKey)
+	return &result, nil
+}
+
+// apiRequest describes a single call against the extraction API.
+type apiRequest struct {
+	op          string // reported to observers
+	method      string
+	path        string
+	contentType string
+	// body returns the request body for one attempt and its length, or -1
+	// if the length is unknown. It is nil for requests without a body.
+	body func() (io.Reader, int64, error)
+	// size is the length of the body counted against the in-flight byte
+	// budget, or -1 if it is unknown.
+	size int64
+	// replayable reports whether body may be called again for a retry.
+	replayable bool
+	opts       callOptions
+	attrs      []slog.Attr // added to log events
+	fileName   string      // reported to observers
+	// endpoint, if set, is the base URL to send to instead of one chosen by
+	// the client.
+	endpoint string
+}
+
+func bytesBody(b []byte) func() (io.Reader, int64, error) {
+	return func() (io.Reader, int64, error) {
+		return bytes.NewReader(b), int64(len(b)), nil
 	}
+}
 
-	if c.Debug {
-		fmt.Printf("DEBUG: Making request to %s with file %s\n", reqURL, fileName)
+// do sends r, retrying according to c.RetryPolicy, and decodes a successful
+// JSON response into out. The call is reported to the client's observers.
+func (c *Client) do(ctx context.Context, r apiRequest, out any) error {
+	observers := c.observers()
+	info := ResponseInfo{RequestInfo: RequestInfo{
+		Operation: r.op,
+		Method:    r.method,
+		URL:       c.BaseURL + r.path,
+		FileName:  r.fileName,
+	}}
+	for _, o := range observers {
+		ctx = o.OnRequestStart(ctx, info.RequestInfo)
+	}
+
+	start := time.Now()
+	err := c.requireVersion(ctx, r)
+	switch {
+	case err != nil:
+		// The service is incompatible, so nothing is sent.
+	case c.breaker != nil && r.op != OpHealthCheck:
+		err = c.allow(ctx)
+		if err == nil {
+			err = c.attempt(ctx, r, out, observers, &info)
+			c.record(ctx, err)
+		}
+	default:
+		err = c.attempt(ctx, r, out, observers, &info)
 	}
+	info.Duration = time.Since(start)
 
-	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		for _, o := range observers {
+			o.OnError(ctx, info, err)
+		}
+		return err
 	}
-	defer func(Body io.ReadCloser) {
-		innerErr := Body.Close()
-		if innerErr != nil {
-			slog.Error("Failed to close response body", "error", err)
+
+	info.PageCount = pageCount(out)
+	for _, o := range observers {
+		o.OnResponse(ctx, info)
+	}
+	return nil
+}
+
+// attempt runs the retry loop of do, accumulating the transfer of every
+// attempt into info.
+func (c *Client) attempt(ctx context.Context, r apiRequest, out any, observers []Observer, info *ResponseInfo) error {
+	maxAttempts := c.RetryPolicy.attempts()
+
+	var errs []error
+	var previous *endpoint
+	for attempt := 1; ; attempt++ {
+		var stats attemptStats
+		queued, err := c.admit(ctx, r.size)
+		info.QueueTime += queued
+		if err == nil {
+			ep, base := c.pickEndpoint(r, previous)
+			sent := time.Now()
+			err = c.send(ctx, r, base, attempt, out, &stats)
+			c.release(r.size)
+			c.adapt(ctx, sent, err)
+			c.endpointDone(ctx, ep, time.Since(sent), err)
+			info.URL = base + r.path
+			previous = ep
 		}
-	}(resp.Body)
+		info.Attempts = attempt
+		info.StatusCode = stats.statusCode
+		info.BytesSent += stats.bytesSent
+		info.BytesReceived += stats.bytesReceived
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		bodyStr := string(body)
-		
-		// Try to parse the error response as JSON
-		var apiError struct {
-			Detail string `json:"detail"`
+		if maxAttempts == 1 {
+			return err
 		}
-		
-		detail := ""
-		if err := json.Unmarshal(body, &apiError); err == nil && apiError.Detail != "" {
-			detail = apiError.Detail
+
+		if attempt >= maxAttempts || ctx.Err() != nil || (r.body != nil && !r.replayable) ||
+			!c.RetryPolicy.shouldRetry(err) {
+			return &RetryError{Attempts: attempt, Errors: errs, Err: err}
 		}
-		
-		return nil, ClientError{
-			StatusCode: resp.StatusCode,
-			Message:    bodyStr,
-			Detail:     detail,
+
+		wait := c.RetryPolicy.delay(attempt, err)
+		c.logger().LogAttrs(ctx, slog.LevelDebug, "pdfclient retrying request",
+			append([]slog.Attr{
+				slog.String("path", r.path),
+				slog.Int("attempt", attempt),
+				slog.Int("max_attempts", maxAttempts),
+				slog.Duration("delay", wait),
+				slog.Any("error", err),
+			}, r.attrs...)...)
+		for _, o := range observers {
+			o.OnRetry(ctx, RetryInfo{RequestInfo: info.RequestInfo, Attempt: attempt, Delay: wait, Err: err})
 		}
-	}
 
-	var result TextExtractionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &RetryError{Attempts: attempt, Errors: errs, Err: errors.Join(ctx.Err(), err)}
+		case <-timer.C:
+		}
 	}
-
-	return &result, nil
 }
 
-func (c *Client) ExtractTextFromGCS(ctx context.Context, request GCSExtractionRequest) (*GCSExtractionResponse, error) {
-	reqURL := fmt.Sprintf("%s/extract-from-gcs", c.BaseURL)
+// send performs a single HTTP round trip to the service at base, recording
+// its transfer in stats.
+func (c *Client) send(ctx context.Context, r apiRequest, base string, attempt int, out any, stats *attemptStats) (err error) {
+	reqURL := base + r.path
+	log := c.logger()
 
-	// Set default method if not provided
-	if request.Method == "" {
-		request.Method = "auto"
+	if c.RequestLimiter != nil {
+		if err := c.RequestLimiter.Wait(ctx, 1); err != nil {
+			return fmt.Errorf("error waiting for rate limit: %w", err)
+		}
 	}
 
-	// Set default output format if not provided
-	if request.OutputFormat == "" {
-		request.OutputFormat = "text"
+	var sent *countingReader
+	var body io.Reader
+	contentLength := int64(0)
+	if r.body != nil {
+		body, contentLength, err = r.body()
+		if err != nil {
+			return err
+		}
+		if c.UploadLimiter != nil {
+			body = &limitedReader{ctx: ctx, r: body, limiter: c.UploadLimiter}
+		}
+		sent = &countingReader{r: body, limit: c.LogBodyLimit}
+		body = sent
 	}
 
-	// Marshal request body
-	jsonBody, err := json.Marshal(request)
+	req, err := http.NewRequestWithContext(ctx, r.method, reqURL, body)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling request: %w", err)
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = contentLength
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
 
-	if c.APIKey != "" {
-		req.Header.Set("X-API-Key", c.APIKey)
-	}
-
-	if c.Debug {
-		fmt.Printf("DEBUG: Making request to %s with GCS URL %s\n", reqURL, request.InputGCSURL)
-	}
+	r.opts.setHeaders(c, req)
+
+	attrs := append([]slog.Attr{
+		slog.String("method", r.method),
+		slog.String("url", reqURL),
+		slog.Int("attempt", attempt),
+	}, r.attrs...)
+	log.LogAttrs(ctx, slog.LevelDebug, "pdfclient request",
+		append(attrs, slog.Any("headers", redactHeaders(req.Header)))...)
+
+	start := time.Now()
+	received := &countingReader{limit: c.LogBodyLimit}
+	statusCode := 0
+	defer func() {
+		stats.statusCode = statusCode
+		stats.bytesReceived = received.n
+		attrs = append(attrs,
+			slog.Duration("duration", time.Since(start)),
+			slog.Int64("bytes_received", received.n))
+		if sent != nil {
+			stats.bytesSent = sent.n
+			attrs = append(attrs, slog.Int64("bytes_sent", sent.n))
+		}
+		if statusCode != 0 {
+			attrs = append(attrs, slog.Int("status", statusCode))
+		}
+		if c.LogBodyLimit > 0 {
+			if sent != nil {
+				attrs = append(attrs, sent.bodyAttr("request_body"))
+			}
+			attrs = append(attrs, received.bodyAttr("response_body"))
+		}
+		if err != nil {
+			log.LogAttrs(ctx, slog.LevelDebug, "pdfclient request failed", append(attrs, slog.Any("error", err))...)
+			return
+		}
+		log.LogAttrs(ctx, slog.LevelDebug, "pdfclient response", attrs...)
+	}()
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := r.opts.httpClient(c).Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
+		return &NetworkError{Method: r.method, URL: reqURL, Endpoint: base, Err: err}
 	}
 	defer func(Body io.ReadCloser) {
 		innerErr := Body.Close()
 		if innerErr != nil {
-			slog.Error("Failed to close response body", "error", innerErr)
+			log.Error("Failed to close response body", "error", innerErr)
 		}
 	}(resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		bodyStr := string(body)
-
-		// Try to parse the error response as JSON
-		var apiError struct {
-			Detail string `json:"detail"`
-		}
+	statusCode = resp.StatusCode
+	received.r = resp.Body
 
-		detail := ""
-		if err := json.Unmarshal(body, &apiError); err == nil && apiError.Detail != "" {
-			detail = apiError.Detail
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(received)
+		if err != nil {
+			return &NetworkError{Method: r.method, URL: reqURL, Endpoint: base, Err: fmt.Errorf("error reading response: %w", err)}
 		}
+		clientErr := newClientError(req, resp, body)
+		clientErr.Endpoint = base
+		return clientErr
+	}
 
-		return nil, ClientError{
-			StatusCode: resp.StatusCode,
-			Message:    bodyStr,
-			Detail:     detail,
+	if err := json.NewDecoder(received).Decode(out); err != nil {
+		if received.err != nil {
+			// The connection failed, rather than the service sending
+			// something other than JSON.
+			return &NetworkError{Method: r.method, URL: reqURL, Endpoint: base, Err: fmt.Errorf("error reading response: %w", received.err)}
 		}
+		return fmt.Errorf("error decoding response: %w", err)
 	}
 
-	var result GCSExtractionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+	switch v := out.(type) {
+	case *HealthResponse:
+		v.Endpoint = base
+	case *TextExtractionResponse:
+		v.Endpoint = base
+	case *GCSExtractionResponse:
+		v.Endpoint = base
 	}
 
-	return &result, nil
+	return nil
 }
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+func runHealth(ctx context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) error {
+	flags, g := newFlagSet("health", stderr)
+	wait := flags.Duration("wait", 0, "poll until the service is healthy, for at most this long")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return usageError{errors.New("health takes no arguments")}
+	}
+
+	client, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	var health *pdfclient.HealthResponse
+	if *wait > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, *wait)
+		defer cancel()
+		health, err = client.WaitUntilHealthy(waitCtx, time.Second)
+	} else {
+		health, err = client.HealthCheck(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if g.output == outputText {
+		_, err = fmt.Fprintf(stdout, "status: %s\nversion: %s\n", health.Status, health.Version)
+		return err
+	}
+
+	return writeJSON(stdout, health, g.output)
+}
+
+func runExtract(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags, g := newFlagSet("extract", stderr)
+	concurrency := flags.Int("concurrency", pdfclient.DefaultBatchConcurrency, "number of files extracted concurrently")
+	failFast := flags.Bool("fail-fast", false, "stop at the first file that fails")
+	stdinName := flags.String("stdin-name", "stdin.pdf", "file name sent for input read from stdin")
+	method := flags.String("method", "", "extraction method: auto, pypdf2 or pdfplumber (default: server choice)")
+	outputFormat := flags.String("output-format", "", "output format requested from the server")
+	cacheDir := flags.String("cache-dir", "", "reuse results cached in this directory")
+	preflight := flags.Bool("preflight", false, "validate files locally and skip invalid ones without uploading them")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	inputs, err := expandInputs(paths, stdin, *stdinName)
+	if err != nil {
+		return err
+	}
+
+	var clientOptions []pdfclient.ClientOption
+	if *cacheDir != "" {
+		cache, err := pdfclient.NewDiskCache(*cacheDir)
+		if err != nil {
+			return err
+		}
+		clientOptions = append(clientOptions,
+			pdfclient.WithCache(cache, pdfclient.CacheOptions{MatchServerVersion: true}))
+	}
+	if *preflight {
+		clientOptions = append(clientOptions, pdfclient.WithPreflight(pdfclient.PreflightOptions{}))
+	}
+
+	client, err := g.newClient(clientOptions...)
+	if err != nil {
+		return err
+	}
+
+	var callOptions []pdfclient.CallOption
+	if *method != "" {
+		callOptions = append(callOptions, pdfclient.WithMethod(*method))
+	}
+	if *outputFormat != "" {
+		callOptions = append(callOptions, pdfclient.WithOutputFormat(*outputFormat))
+	}
+
+	// Results are written as each file finishes, except for JSON output,
+	// which is a single array written at the end.
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan pdfclient.BatchResult)
+	var batchErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, batchErr = client.ExtractBatch(batchCtx, inputs, pdfclient.BatchOptions{
+			Concurrency: *concurrency,
+			FailFast:    *failFast,
+			CallOptions: callOptions,
+			Results:     results,
+		})
+	}()
+
+	out := newResultWriter(stdout, g.output, len(inputs) > 1)
+	var (
+		firstErr      error
+		firstErrIndex int
+		writeErr      error
+	)
+	for result := range results {
+		if result.Err != nil {
+			// The exit code reflects the first failed input, whatever order
+			// the files finish in.
+			if firstErr == nil || result.Index < firstErrIndex {
+				firstErr, firstErrIndex = result.Err, result.Index
+			}
+			if !errors.Is(result.Err, pdfclient.ErrBatchAborted) {
+				fmt.Fprintf(stderr, "pdftotext-client extract: %s: %v\n", result.FileName, result.Err)
+			}
+		}
+		if writeErr != nil {
+			continue
+		}
+		if err := out.write(result); err != nil {
+			// Stop the batch, but keep receiving until it has finished.
+			writeErr = err
+			cancel()
+		}
+	}
+	<-done
+
+	if writeErr != nil {
+		return writeErr
+	}
+	if err := out.close(); err != nil {
+		return err
+	}
+
+	if batchErr != nil {
+		if batchErr != batchCtx.Err() {
+			// The fail-fast error has already been reported with its file.
+			return reportedError{batchErr}
+		}
+		return batchErr
+	}
+	if firstErr != nil {
+		// Per-file errors have already been reported.
+		return reportedError{firstErr}
+	}
+	return nil
+}
+
+func runGCS(ctx context.Context, args []string, _ io.Reader, stdout, stderr io.Writer) error {
+	flags, g := newFlagSet("gcs", stderr)
+	dest := flags.String("dest", "", "GCS URL to write the extracted text to")
+	method := flags.String("method", "auto", "extraction method: auto, pypdf2 or pdfplumber")
+	outputFormat := flags.String("output-format", "text", "format of the file written to -dest")
+	projectID := flags.String("project", "", "Google Cloud project ID")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return usageError{errors.New("gcs takes exactly one gs:// input URL")}
+	}
+
+	request := pdfclient.GCSExtractionRequest{
+		InputGCSURL:  flags.Arg(0),
+		Method:       *method,
+		OutputFormat: *outputFormat,
+	}
+	if *dest != "" {
+		request.OutputGCSURL = dest
+	}
+	if *projectID != "" {
+		request.ProjectID = projectID
+	}
+
+	client, err := g.newClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := client.ExtractTextFromGCS(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if g.output == outputText {
+		_, err = fmt.Fprintln(stdout, result.GetFullText())
+		return err
+	}
+
+	return writeJSON(stdout, result, g.output)
+}
+
+// expandInputs turns the command line arguments into batch inputs. Each
+// argument may be a file, a glob pattern, a directory (searched recursively
+// for .pdf files) or "-" for stdin.
+func expandInputs(args []string, stdin io.Reader, stdinName string) ([]pdfclient.BatchInput, error) {
+	var inputs []pdfclient.BatchInput
+	usedStdin := false
+
+	for _, arg := range args {
+		if arg == "-" {
+			if usedStdin {
+				return nil, usageError{errors.New("stdin (-) given more than once")}
+			}
+			usedStdin = true
+			inputs = append(inputs, pdfclient.ReaderInput(stdin, stdinName))
+			continue
+		}
+
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			matches, err = filepath.Glob(arg)
+			if err != nil {
+				return nil, usageError{fmt.Errorf("invalid pattern %q: %w", arg, err)}
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", arg)
+			}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+
+			if !info.IsDir() {
+				inputs = append(inputs, pdfclient.FileInput(match))
+				continue
+			}
+
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".pdf") {
+					inputs = append(inputs, pdfclient.FileInput(path))
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, errors.New("no PDF files found")
+	}
+
+	return inputs, nil
+}
+
+// reportedError wraps an error that has already been printed, so that run
+// only needs to turn it into an exit code.
+type reportedError struct {
+	err error
+}
+
+func (e reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e reportedError) Unwrap() error {
+	return e.err
+}
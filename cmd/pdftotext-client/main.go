@@ -0,0 +1,222 @@
+// Command pdftotext-client is a command-line client for the PDF Text
+// Extraction API.
+//
+// Usage:
+//
+//	pdftotext-client health [-wait duration]
+//	pdftotext-client extract [flags] [file|glob|dir|-]...
+//	pdftotext-client gcs [flags] gs://bucket/input.pdf
+//
+// The server URL and API key are read from -url and -api-key, or from the
+// PDFTOTEXT_URL and PDFTOTEXT_API_KEY environment variables.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+// Exit codes returned by the command. They let shell pipelines branch on the
+// category of a failure.
+const (
+	exitOK            = 0
+	exitError         = 1
+	exitUsage         = 2
+	exitInvalidPDF    = 3
+	exitTooLarge      = 4
+	exitTimeout       = 5
+	exitGCSPermission = 6
+	exitGCSNotFound   = 7
+)
+
+const (
+	envURL    = "PDFTOTEXT_URL"
+	envAPIKey = "PDFTOTEXT_API_KEY"
+
+	defaultURL = "http://localhost:8000"
+)
+
+const usage = `Usage: pdftotext-client <command> [flags] [args]
+
+Commands:
+  health    Check the health of the extraction service
+  extract   Extract text from local PDF files, globs, directories or stdin (-)
+  gcs       Extract text from a PDF stored in Google Cloud Storage
+
+Run 'pdftotext-client <command> -h' for the flags of a command.
+
+Environment:
+  ` + envURL + `       Base URL of the service (default ` + defaultURL + `)
+  ` + envAPIKey + `   API key sent as X-API-Key
+
+Exit codes:
+  0 success, 1 error, 2 usage, 3 invalid PDF, 4 file too large,
+  5 timeout, 6 GCS permission denied, 7 GCS object not found
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the process exit code.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var cmd func(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error
+	switch args[0] {
+	case "health":
+		cmd = runHealth
+	case "extract":
+		cmd = runExtract
+	case "gcs":
+		cmd = runGCS
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	err := cmd(ctx, args[1:], stdin, stdout, stderr)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errFlagParse):
+		// The flag package has already reported the problem.
+		return exitUsage
+	case errors.As(err, new(reportedError)):
+		return exitCode(err)
+	}
+
+	fmt.Fprintf(stderr, "pdftotext-client %s: %v\n", args[0], err)
+	return exitCode(err)
+}
+
+// errFlagParse is returned by parseFlags when the command line is invalid.
+var errFlagParse = errors.New("invalid flags")
+
+// globalFlags are the flags shared by every command.
+type globalFlags struct {
+	url     string
+	apiKey  string
+	timeout time.Duration
+	retries int
+	output  string
+	debug   bool
+}
+
+// newFlagSet returns a flag set for the named command with the global flags
+// registered.
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *globalFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	g := &globalFlags{}
+
+	url := os.Getenv(envURL)
+	if url == "" {
+		url = defaultURL
+	}
+
+	fs.StringVar(&g.url, "url", url, "base URL of the extraction service (env "+envURL+")")
+	fs.StringVar(&g.apiKey, "api-key", os.Getenv(envAPIKey), "API key (env "+envAPIKey+")")
+	fs.DurationVar(&g.timeout, "timeout", 120*time.Second, "timeout of each request")
+	fs.IntVar(&g.retries, "retries", 0, "number of retries of transient failures")
+	fs.StringVar(&g.output, "o", outputText, "output format: text, json or jsonl")
+	fs.BoolVar(&g.debug, "debug", false, "log requests to stderr")
+
+	return fs, g
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errFlagParse
+	}
+	return nil
+}
+
+func (g *globalFlags) newClient(extra ...pdfclient.ClientOption) (*pdfclient.Client, error) {
+	switch g.output {
+	case outputText, outputJSON, outputJSONL:
+	default:
+		return nil, usageError{fmt.Errorf("invalid output format %q", g.output)}
+	}
+
+	options := []pdfclient.ClientOption{
+		pdfclient.WithTimeout(g.timeout),
+		pdfclient.WithDebug(g.debug),
+		pdfclient.WithUserAgent("pdftotext-client/1.0"),
+	}
+
+	if g.apiKey != "" {
+		options = append(options, pdfclient.WithAPIKey(g.apiKey))
+	}
+
+	if g.retries > 0 {
+		policy := pdfclient.DefaultRetryPolicy()
+		policy.MaxAttempts = g.retries + 1
+		options = append(options, pdfclient.WithRetryPolicy(policy))
+	}
+
+	return pdfclient.NewClient(g.url, append(options, extra...)...)
+}
+
+// usageError marks errors caused by invalid command line arguments.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// exitCode maps err to the exit code of its error category.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		return exitUsage
+	}
+
+	switch {
+	case errors.Is(err, pdfclient.ErrInvalidPDF):
+		return exitInvalidPDF
+	case errors.Is(err, pdfclient.ErrTooLarge):
+		return exitTooLarge
+	case errors.Is(err, pdfclient.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.Is(err, pdfclient.ErrGCSPermission):
+		return exitGCSPermission
+	case errors.Is(err, pdfclient.ErrGCSNotFound):
+		return exitGCSNotFound
+	}
+
+	return exitError
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write([]byte(`{"status":"ok","version":"1.0.0"}`))
+		case "/extract":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			content, _ := io.ReadAll(file)
+			if !bytes.HasPrefix(content, []byte("%PDF")) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"detail":"Invalid PDF format"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"pages":[{"page":1,"text":"text of ` + header.Filename + `"}],"page_count":1,"file_name":"` + header.Filename + `","file_size":4}`))
+		case "/extract-from-gcs":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"detail":"Permission denied accessing gs://bucket/file.pdf"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Health(t *testing.T) {
+	server := newTestServer(t)
+
+	code, stdout, stderr := runCommand(t, "", "health", "-url", server.URL, "-o", "json")
+	if code != exitOK {
+		t.Fatalf("exit code = %v, want %v (stderr: %s)", code, exitOK, stderr)
+	}
+
+	var health pdfclient.HealthResponse
+	if err := json.Unmarshal([]byte(stdout), &health); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", stdout, err)
+	}
+
+	if health.Status != "ok" {
+		t.Errorf("health status = %v, want %v", health.Status, "ok")
+	}
+}
+
+func TestRun_HealthWait(t *testing.T) {
+	var checks atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if checks.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"detail":"starting"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","version":"1.0.0"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	code, stdout, stderr := runCommand(t, "", "health", "-url", server.URL, "-wait", "10s")
+	if code != exitOK {
+		t.Fatalf("exit code = %v, want %v (stderr: %s)", code, exitOK, stderr)
+	}
+	if !strings.Contains(stdout, "status: ok") {
+		t.Errorf("stdout = %q, want the healthy status", stdout)
+	}
+	if n := checks.Load(); n != 2 {
+		t.Errorf("server received %d health checks, want %d", n, 2)
+	}
+}
+
+func TestRun_ExtractDirectoryAsJSONLines(t *testing.T) {
+	server := newTestServer(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.pdf":      "%PDF-1.7",
+		"b.PDF":      "%PDF-1.7",
+		"notes.txt":  "ignored",
+		"broken.pdf": "<html>",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	code, stdout, _ := runCommand(t, "", "extract", "-url", server.URL, "-o", "jsonl", dir)
+	if code != exitInvalidPDF {
+		t.Errorf("exit code = %v, want %v", code, exitInvalidPDF)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("output lines = %v, want 3:\n%s", len(lines), stdout)
+	}
+
+	for _, line := range lines {
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		if r.File == "broken.pdf" {
+			if r.Error == nil || r.Error.ExitCode != exitInvalidPDF {
+				t.Errorf("broken.pdf error = %+v, want invalid PDF", r.Error)
+			}
+		} else if r.Result == nil || r.Result.GetFullText() != "text of "+r.File {
+			t.Errorf("%s result = %+v", r.File, r.Result)
+		}
+	}
+}
+
+func TestRun_ExtractStdin(t *testing.T) {
+	server := newTestServer(t)
+
+	code, stdout, stderr := runCommand(t, "%PDF-1.7", "extract", "-url", server.URL, "-stdin-name", "in.pdf", "-")
+	if code != exitOK {
+		t.Fatalf("exit code = %v, want %v (stderr: %s)", code, exitOK, stderr)
+	}
+
+	if stdout != "text of in.pdf\n" {
+		t.Errorf("stdout = %q, want %q", stdout, "text of in.pdf\n")
+	}
+}
+
+// notifyWriter passes each write to a channel.
+type notifyWriter chan string
+
+func (w notifyWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestRun_ExtractStreamsResults(t *testing.T) {
+	release := make(chan struct{})
+	handler := newTestServer(t).Config.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, header, err := r.FormFile("file"); err == nil && header.Filename == "slow.pdf" {
+			<-release
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	releaseSlow := sync.OnceFunc(func() { close(release) })
+	t.Cleanup(releaseSlow)
+
+	dir := t.TempDir()
+	for _, name := range []string{"fast.pdf", "slow.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("%PDF-1.7"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	stdout := make(notifyWriter, 16)
+	code := make(chan int, 1)
+	go func() {
+		args := []string{"extract", "-url", server.URL, "-o", "jsonl", "-concurrency", "2", dir}
+		code <- run(context.Background(), args, strings.NewReader(""), stdout, io.Discard)
+	}()
+
+	// The fast file is written while the slow one is still being extracted.
+	select {
+	case line := <-stdout:
+		if !strings.Contains(line, "text of fast.pdf") {
+			t.Errorf("first output = %q, want the result of fast.pdf", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the first result")
+	}
+	releaseSlow()
+
+	if got := <-code; got != exitOK {
+		t.Errorf("exit code = %v, want %v", got, exitOK)
+	}
+	if line := <-stdout; !strings.Contains(line, "text of slow.pdf") {
+		t.Errorf("second output = %q, want the result of slow.pdf", line)
+	}
+}
+
+func TestRun_ExtractCacheDir(t *testing.T) {
+	uploads := 0
+	server := newTestServer(t)
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/extract" {
+			uploads++
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.7"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	for i := 0; i < 2; i++ {
+		code, stdout, stderr := runCommand(t, "", "extract", "-url", server.URL, "-cache-dir", cacheDir, path)
+		if code != exitOK {
+			t.Fatalf("exit code = %v, want %v (stderr: %s)", code, exitOK, stderr)
+		}
+		if stdout != "text of a.pdf\n" {
+			t.Errorf("stdout = %q, want %q", stdout, "text of a.pdf\n")
+		}
+	}
+
+	if uploads != 1 {
+		t.Errorf("server received %d uploads, want %d", uploads, 1)
+	}
+}
+
+func TestRun_ExtractPreflight(t *testing.T) {
+	uploads := 0
+	server := newTestServer(t)
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/extract" {
+			uploads++
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	path := filepath.Join(t.TempDir(), "truncated.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.7\n1 0 obj\n<<"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	code, _, stderr := runCommand(t, "", "extract", "-url", server.URL, "-preflight", path)
+	if code != exitInvalidPDF {
+		t.Errorf("exit code = %v, want %v (stderr: %s)", code, exitInvalidPDF, stderr)
+	}
+	if uploads != 0 {
+		t.Errorf("server received %d uploads, want %d", uploads, 0)
+	}
+}
+
+func TestRun_ExtractFailFastReportsOnce(t *testing.T) {
+	server := newTestServer(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.pdf": "<html>", "b.pdf": "%PDF-1.7"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	code, _, stderr := runCommand(t, "", "extract", "-url", server.URL, "-fail-fast", "-concurrency", "1", dir)
+	if code != exitInvalidPDF {
+		t.Errorf("exit code = %v, want %v (stderr: %s)", code, exitInvalidPDF, stderr)
+	}
+	if n := strings.Count(stderr, "API error"); n != 1 {
+		t.Errorf("stderr reports the failure %d times, want once:\n%s", n, stderr)
+	}
+	if !strings.Contains(stderr, "a.pdf") {
+		t.Errorf("stderr = %q, want the failed file named", stderr)
+	}
+}
+
+func TestRun_GCSPermissionExitCode(t *testing.T) {
+	server := newTestServer(t)
+
+	code, _, stderr := runCommand(t, "", "gcs", "-url", server.URL, "gs://bucket/file.pdf")
+	if code != exitGCSPermission {
+		t.Errorf("exit code = %v, want %v (stderr: %s)", code, exitGCSPermission, stderr)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no command", args: nil},
+		{name: "unknown command", args: []string{"convert"}},
+		{name: "unknown flag", args: []string{"health", "-bogus"}},
+		{name: "bad output format", args: []string{"health", "-o", "xml"}},
+		{name: "missing GCS URL", args: []string{"gcs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, _ := runCommand(t, "", tt.args...); code != exitUsage {
+				t.Errorf("exit code = %v, want %v", code, exitUsage)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+)
+
+// record is the JSON representation of the extraction of a single file.
+type record struct {
+	File       string                            `json:"file"`
+	Result     *pdfclient.TextExtractionResponse `json:"result,omitempty"`
+	Error      *errorRecord                      `json:"error,omitempty"`
+	DurationMS int64                             `json:"duration_ms"`
+
+	index int // position of the input, for ordering JSON output
+}
+
+type errorRecord struct {
+	Message    string `json:"message"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	ExitCode   int    `json:"exit_code"`
+}
+
+func newRecord(result pdfclient.BatchResult) record {
+	r := record{
+		File:       result.FileName,
+		Result:     result.Response,
+		DurationMS: result.Duration.Milliseconds(),
+		index:      result.Index,
+	}
+
+	if result.Err != nil {
+		r.Error = &errorRecord{
+			Message:  result.Err.Error(),
+			ExitCode: exitCode(result.Err),
+		}
+
+		var clientErr *pdfclient.ClientError
+		if errors.As(result.Err, &clientErr) {
+			r.Error.StatusCode = clientErr.StatusCode
+			r.Error.Detail = clientErr.Detail
+		}
+	}
+
+	return r
+}
+
+// resultWriter renders batch results in one of the output formats. Text
+// output separates multiple files with a header line and JSON Lines output is
+// one record per line, both written as results arrive. JSON output is a
+// single array in input order, written by close.
+type resultWriter struct {
+	w       io.Writer
+	format  string
+	headers bool
+	records []record
+	count   int
+}
+
+func newResultWriter(w io.Writer, format string, headers bool) *resultWriter {
+	return &resultWriter{w: w, format: format, headers: headers}
+}
+
+func (rw *resultWriter) write(result pdfclient.BatchResult) error {
+	switch rw.format {
+	case outputJSON:
+		rw.records = append(rw.records, newRecord(result))
+		return nil
+	case outputJSONL:
+		return writeJSON(rw.w, newRecord(result), outputJSONL)
+	}
+
+	if result.Response == nil {
+		return nil
+	}
+
+	if rw.headers {
+		if rw.count > 0 {
+			if _, err := fmt.Fprintln(rw.w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(rw.w, "==> %s <==\n", result.FileName); err != nil {
+			return err
+		}
+	}
+	rw.count++
+	_, err := fmt.Fprintln(rw.w, result.Response.GetFullText())
+	return err
+}
+
+func (rw *resultWriter) close() error {
+	if rw.format != outputJSON {
+		return nil
+	}
+	if rw.records == nil {
+		rw.records = []record{}
+	}
+	slices.SortFunc(rw.records, func(a, b record) int { return cmp.Compare(a.index, b.index) })
+	return writeJSON(rw.w, rw.records, outputJSON)
+}
+
+// writeJSON writes v indented for JSON output and on a single line for JSON
+// Lines output.
+func writeJSON(w io.Writer, v any, format string) error {
+	encoder := json.NewEncoder(w)
+	if format == outputJSON {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
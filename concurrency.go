@@ -0,0 +1,150 @@
+package pdfclient
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WithMaxConcurrent limits the number of requests the client has in flight.
+// Further calls wait in FIFO order for a request to finish.
+func WithMaxConcurrent(n int) ClientOption {
+	return func(c *Client) {
+		c.concurrency = newSemaphore(int64(max(n, 1)))
+		c.adaptive = nil
+	}
+}
+
+// WithMaxInFlightBytes limits the total size of the request bodies the client
+// has in flight. Further calls wait in FIFO order for uploads to finish. A
+// body larger than the budget is sent on its own; bodies of unknown length
+// and requests without a body count only towards WithMaxConcurrent.
+func WithMaxInFlightBytes(n int64) ClientOption {
+	return func(c *Client) {
+		c.inFlightBytes = newSemaphore(max(n, 1))
+	}
+}
+
+// admit waits until the client may send a body of size bytes and returns the
+// time spent waiting. When err is nil the caller must call c.release.
+func (c *Client) admit(ctx context.Context, size int64) (time.Duration, error) {
+	if c.concurrency == nil && c.inFlightBytes == nil {
+		return 0, nil
+	}
+
+	start := time.Now()
+	if c.concurrency != nil {
+		if err := c.concurrency.acquire(ctx, 1); err != nil {
+			return time.Since(start), fmt.Errorf("error waiting for a request slot: %w", err)
+		}
+	}
+	if c.inFlightBytes != nil && size > 0 {
+		if err := c.inFlightBytes.acquire(ctx, c.inFlightBytes.weight(size)); err != nil {
+			if c.concurrency != nil {
+				c.concurrency.release(1)
+			}
+			return time.Since(start), fmt.Errorf("error waiting for upload budget: %w", err)
+		}
+	}
+	return time.Since(start), nil
+}
+
+// release returns what admit acquired for a body of size bytes.
+func (c *Client) release(size int64) {
+	if c.inFlightBytes != nil && size > 0 {
+		c.inFlightBytes.release(c.inFlightBytes.weight(size))
+	}
+	if c.concurrency != nil {
+		c.concurrency.release(1)
+	}
+}
+
+// semaphore is a weighted semaphore granting waiters in FIFO order. A large
+// waiter at the front blocks smaller ones behind it, so it cannot starve.
+type semaphore struct {
+	mu      sync.Mutex
+	size    int64
+	cur     int64
+	waiters list.List // of semaphoreWaiter
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+func newSemaphore(size int64) *semaphore {
+	return &semaphore{size: size}
+}
+
+// weight returns the units taken by a body of size bytes.
+func (s *semaphore) weight(size int64) int64 {
+	return min(size, s.size)
+}
+
+func (s *semaphore) acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := semaphoreWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// Granted while cancelling; give it back.
+			s.cur -= n
+		default:
+			s.waiters.Remove(elem)
+		}
+		s.notify()
+		return ctx.Err()
+	}
+}
+
+func (s *semaphore) release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cur -= n
+	s.notify()
+}
+
+// resize changes the size of the semaphore. Shrinking it does not interrupt
+// holders; new waiters are granted once enough has been released.
+func (s *semaphore) resize(size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.size = size
+	s.notify()
+}
+
+// notify grants waiters from the front of the queue while they fit.
+func (s *semaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
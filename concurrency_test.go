@@ -0,0 +1,202 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+// gatedServer holds every request until release is called, recording how
+// many were in flight at once and the order of their User-Agent headers.
+type gatedServer struct {
+	*httptest.Server
+	gate        chan struct{}
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+
+	mu    sync.Mutex
+	order []string
+}
+
+func newGatedServer() *gatedServer {
+	s := &gatedServer{gate: make(chan struct{})}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		for {
+			m := s.maxInFlight.Load()
+			if n <= m || s.maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		s.mu.Lock()
+		s.order = append(s.order, r.Header.Get("X-Order"))
+		s.mu.Unlock()
+
+		<-s.gate
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"healthy","version":"1.0.0","pages":[],"page_count":0}`))
+	}))
+	return s
+}
+
+func (s *gatedServer) release() {
+	close(s.gate)
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	server := newGatedServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithMaxConcurrent(2))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.HealthCheck(context.Background()); err != nil {
+				t.Errorf("HealthCheck() error = %v", err)
+			}
+		}()
+	}
+
+	waitFor(t, "two requests in flight and four queued", func() bool {
+		return server.inFlight.Load() == 2 && pdfclient.QueuedCalls(client) == 4
+	})
+	server.release()
+	wg.Wait()
+
+	if got := server.maxInFlight.Load(); got != 2 {
+		t.Errorf("max requests in flight = %v, want %v", got, 2)
+	}
+
+	stats := client.Stats().Operations[pdfclient.OpHealthCheck]
+	if stats.QueueTime.Max <= 0 {
+		t.Errorf("Stats() queue time = %+v, want queued calls to be measured", stats.QueueTime)
+	}
+}
+
+func TestMaxConcurrent_FIFO(t *testing.T) {
+	server := newGatedServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithMaxConcurrent(1))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	want := []string{"0", "1", "2", "3", "4"}
+	for i, order := range want {
+		wg.Add(1)
+		go func(order string) {
+			defer wg.Done()
+			_, err := client.HealthCheck(context.Background(), pdfclient.WithHeader("X-Order", order))
+			if err != nil {
+				t.Errorf("HealthCheck() error = %v", err)
+			}
+		}(order)
+		// Let each call join the queue before the next.
+		waitFor(t, "the call to be sent or queued", func() bool {
+			return int(server.inFlight.Load())+pdfclient.QueuedCalls(client) == i+1
+		})
+	}
+
+	server.release()
+	wg.Wait()
+
+	for i := range want {
+		if server.order[i] != want[i] {
+			t.Fatalf("requests sent in order %v, want %v", server.order, want)
+		}
+	}
+}
+
+func TestMaxConcurrent_Cancelled(t *testing.T) {
+	server := newGatedServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithMaxConcurrent(1))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.HealthCheck(context.Background())
+	}()
+	waitFor(t, "the first request to reach the server", func() bool {
+		return server.inFlight.Load() == 1
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = client.HealthCheck(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HealthCheck() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	server.release()
+	<-done
+
+	if len(server.order) != 1 {
+		t.Errorf("server received %d requests, want %d", len(server.order), 1)
+	}
+
+	// The cancelled waiter must not hold on to the slot.
+	if _, err := client.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v", err)
+	}
+}
+
+func TestMaxInFlightBytes(t *testing.T) {
+	server := newGatedServer()
+	defer server.Close()
+
+	// Room for one upload of testPDF at a time, plus any number of health
+	// checks, which have no body.
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithMaxInFlightBytes(400))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf"); err != nil {
+				t.Errorf("ExtractTextFromBytes() error = %v", err)
+			}
+		}()
+	}
+	waitFor(t, "one upload in flight and two queued", func() bool {
+		return server.inFlight.Load() == 1 && pdfclient.QueuedCalls(client) == 2
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go func() {
+		client.HealthCheck(ctx)
+	}()
+	waitFor(t, "a health check alongside the upload", func() bool {
+		return server.inFlight.Load() == 2
+	})
+
+	server.release()
+	wg.Wait()
+}
@@ -0,0 +1,226 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// WithDeduplication coalesces concurrent extractions of identical content:
+// calls with the same SHA-256, method, output format, API key and headers
+// share a single upload, and each receives its own copy of the result. The
+// shared request uses the call options of the first caller and is cancelled
+// only when every caller waiting for it has gone.
+func WithDeduplication(enabled bool) ClientOption {
+	return func(c *Client) {
+		if enabled {
+			c.flights = &flightGroup{calls: make(map[string]*flight)}
+		} else {
+			c.flights = nil
+		}
+	}
+}
+
+// flightKey returns the key identifying calls that may share a request.
+func (o callOptions) flightKey(contentKey string) string {
+	var b strings.Builder
+	b.WriteString(contentKey)
+	if o.apiKey != nil {
+		fmt.Fprintf(&b, "\x00api_key=%s", *o.apiKey)
+	}
+	names := make([]string, 0, len(o.header))
+	for name := range o.header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\x00%s=%q", name, o.header[name])
+	}
+	return b.String()
+}
+
+// flightGroup tracks the shared requests in progress.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	result  *TextExtractionResponse
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+	source  *sharedSource
+}
+
+// do runs extract once for all concurrent callers with the same key. Each
+// caller contributes its reader, holding the same content, so the upload can
+// continue from another caller's reader when the one in use goes away.
+func (g *flightGroup) do(ctx context.Context, key string, reader io.ReadSeeker, size int64, fileName string,
+	extract func(context.Context, io.Reader) (*TextExtractionResponse, error)) (*TextExtractionResponse, error) {
+	start, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file offset: %w", err)
+	}
+
+	g.mu.Lock()
+	f, joined := g.calls[key]
+	if !joined {
+		f = &flight{
+			done:   make(chan struct{}),
+			source: &sharedSource{size: size},
+		}
+		g.calls[key] = f
+	}
+	f.waiters++
+	src := f.source.add(reader, start)
+	if !joined {
+		// The upload starts only once the first caller's reader has been
+		// added, so the source is never read without one. The shared
+		// request keeps the first caller's context values, such as trace
+		// spans, but not its cancellation.
+		sharedCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f.cancel = cancel
+		go g.run(sharedCtx, key, f, extract)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		if f.err != nil {
+			return nil, f.err
+		}
+		result := *f.result
+		result.Pages = slices.Clone(f.result.Pages)
+		result.FileName = fileName
+		return &result, nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			if g.calls[key] == f {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+
+		// Wait for any read of this caller's reader to finish, so the
+		// caller may close it once we return.
+		f.source.remove(src)
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, f *flight,
+	extract func(context.Context, io.Reader) (*TextExtractionResponse, error)) {
+	defer f.cancel()
+
+	f.result, f.err = extract(ctx, f.source)
+
+	g.mu.Lock()
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+	g.mu.Unlock()
+	close(f.done)
+}
+
+var errNoSource = errors.New("every caller of the shared extraction has gone")
+
+// sharedSource reads content of a known size from whichever of the waiting
+// callers' readers is available, seeking it to the current offset when the
+// reader in use changes.
+type sharedSource struct {
+	mu      sync.Mutex
+	readers []*sourceReader
+	size    int64
+	offset  int64
+	seek    bool // the first reader must be positioned at offset
+}
+
+type sourceReader struct {
+	r     io.ReadSeeker
+	start int64
+}
+
+func (s *sharedSource) add(r io.ReadSeeker, start int64) *sourceReader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src := &sourceReader{r: r, start: start}
+	if len(s.readers) == 0 {
+		s.seek = true
+	}
+	s.readers = append(s.readers, src)
+	return src
+}
+
+func (s *sharedSource) remove(src *sourceReader) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.Index(s.readers, src)
+	if i < 0 {
+		return
+	}
+	if i == 0 {
+		s.seek = true
+	}
+	s.readers = slices.Delete(s.readers, i, i+1)
+}
+
+func (s *sharedSource) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.readers) == 0 {
+		return 0, errNoSource
+	}
+	src := s.readers[0]
+	if s.seek {
+		if _, err := src.r.Seek(src.start+s.offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("error seeking file data: %w", err)
+		}
+		s.seek = false
+	}
+
+	n, err := src.r.Read(p)
+	s.offset += int64(n)
+	return n, err
+}
+
+func (s *sharedSource) Seek(offset int64, whence int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	s.offset = offset
+	s.seek = true
+	return offset, nil
+}
+
+// Len returns the number of bytes left, so uploads of a shared source carry
+// a Content-Length.
+func (s *sharedSource) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int(max(s.size-s.offset, 0))
+}
@@ -0,0 +1,317 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+// waitFor polls cond until it holds, failing the test if it does not
+// within a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// holdRequests starts a server that passes each request on to server once
+// release receives a value or is closed, so tests can keep requests in
+// flight. Requests cancelled while held are dropped.
+func holdRequests(t *testing.T, server *pdfclienttest.Server, release <-chan struct{}) *httptest.Server {
+	t.Helper()
+	handler := server.Config.Handler
+	held := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(held.Close)
+	return held
+}
+
+// waitForWaiters waits until n callers are waiting for shared extractions.
+func waitForWaiters(t *testing.T, client *pdfclient.Client, n int) {
+	t.Helper()
+	waitFor(t, fmt.Sprintf("%d callers to wait for shared extractions", n), func() bool {
+		return pdfclient.FlightWaiters(client) == n
+	})
+}
+
+func TestDeduplication(t *testing.T) {
+	release := make(chan struct{})
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+	held := holdRequests(t, server, release)
+	defer close(release)
+
+	client, err := pdfclient.NewClient(held.URL, pdfclient.WithDeduplication(true))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	results := make([]*pdfclient.TextExtractionResponse, 5)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			results[i], err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), fmt.Sprintf("%d.pdf", i))
+			if err != nil {
+				t.Errorf("ExtractTextFromBytes() error = %v", err)
+			}
+		}(i)
+	}
+	waitForWaiters(t, client, len(results))
+	release <- struct{}{}
+	wg.Wait()
+
+	if n := countRequests(server, "/extract"); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		if want := fmt.Sprintf("%d.pdf", i); result.FileName != want {
+			t.Errorf("result %d file name = %v, want %v", i, result.FileName, want)
+		}
+		if i > 0 && results[0] != nil && &result.Pages[0] == &results[0].Pages[0] {
+			t.Errorf("result %d shares pages with result 0, want a copy", i)
+		}
+	}
+}
+
+func TestDeduplication_DifferentOptions(t *testing.T) {
+	release := make(chan struct{})
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+	held := holdRequests(t, server, release)
+	defer close(release)
+
+	client, err := pdfclient.NewClient(held.URL, pdfclient.WithDeduplication(true))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	methods := []string{"pypdf2", "pdfplumber"}
+	for _, method := range methods {
+		wg.Add(1)
+		go func(method string) {
+			defer wg.Done()
+			_, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf", pdfclient.WithMethod(method))
+			if err != nil {
+				t.Errorf("ExtractTextFromBytes() error = %v", err)
+			}
+		}(method)
+	}
+	// Both calls are in progress at once, each in its own flight.
+	waitForWaiters(t, client, len(methods))
+	for range methods {
+		release <- struct{}{}
+	}
+	wg.Wait()
+
+	if n := countRequests(server, "/extract"); n != 2 {
+		t.Errorf("server received %d uploads, want %d", n, 2)
+	}
+}
+
+func TestDeduplication_CallerCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+	held := holdRequests(t, server, release)
+	defer close(release)
+
+	client, err := pdfclient.NewClient(held.URL, pdfclient.WithDeduplication(true))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.ExtractTextFromBytes(ctx, []byte(testPDF), "first.pdf")
+		first <- err
+	}()
+	waitForWaiters(t, client, 1)
+
+	type result struct {
+		response *pdfclient.TextExtractionResponse
+		err      error
+	}
+	second := make(chan result, 1)
+	go func() {
+		response, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "second.pdf")
+		second <- result{response, err}
+	}()
+	waitForWaiters(t, client, 2)
+
+	// The first caller leaves while the shared request is still held.
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled ExtractTextFromBytes() error = %v, want context.Canceled", err)
+	}
+	release <- struct{}{}
+
+	r := <-second
+	if r.err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v, want the shared request to survive the first caller", r.err)
+	}
+	if r.response.FileName != "second.pdf" {
+		t.Errorf("ExtractTextFromBytes() file name = %v, want %v", r.response.FileName, "second.pdf")
+	}
+
+	if n := countRequests(server, "/extract"); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+}
+
+func TestDeduplication_AllCallersCancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+	held := holdRequests(t, server, release)
+
+	client, err := pdfclient.NewClient(held.URL, pdfclient.WithDeduplication(true))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.ExtractTextFromBytes(ctx, []byte(testPDF), "a.pdf")
+		first <- err
+	}()
+	waitForWaiters(t, client, 1)
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("ExtractTextFromBytes() error = %v, want context.Canceled", err)
+	}
+
+	// The abandoned request is cancelled rather than joined by later calls.
+	close(release)
+	if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "a.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	waitFor(t, "the abandoned request to finish", func() bool {
+		return client.Stats().Operations[pdfclient.OpExtract].InFlight == 0
+	})
+	stats := client.Stats().Operations[pdfclient.OpExtract]
+	if stats.Requests != 2 || stats.Failed != 1 {
+		t.Errorf("Stats() extract = %+v, want 2 requests of which 1 failed", stats)
+	}
+}
+
+// closableReader fails reads once closed, like an *os.File. reading is
+// closed on the first read.
+type closableReader struct {
+	*bytes.Reader
+	mu      sync.Mutex
+	closed  bool
+	reading chan struct{}
+}
+
+func newClosableReader(data []byte) *closableReader {
+	return &closableReader{Reader: bytes.NewReader(data), reading: make(chan struct{})}
+}
+
+func (r *closableReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, errors.New("read from closed reader")
+	}
+	select {
+	case <-r.reading:
+	default:
+		close(r.reading)
+	}
+	return r.Reader.Read(p)
+}
+
+func (r *closableReader) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+}
+
+func TestDeduplication_UploadHandOff(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	// Throttle the upload so the first caller leaves part way through it.
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithDeduplication(true),
+		pdfclient.WithUploadRateLimit(64<<10))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	pdf := []byte(testPDF + strings.Repeat(" ", 96<<10) + "\n%%EOF\n")
+	first := newClosableReader(pdf)
+	second := newClosableReader(pdf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.ExtractTextFromReader(ctx, first, "a.pdf")
+		first.Close()
+		done <- err
+	}()
+	<-first.reading
+	waitForWaiters(t, client, 1)
+
+	joined := make(chan error, 1)
+	go func() {
+		_, err := client.ExtractTextFromReader(context.Background(), second, "a.pdf")
+		joined <- err
+	}()
+	waitForWaiters(t, client, 2)
+
+	// The first caller leaves part way through the throttled upload.
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled ExtractTextFromReader() error = %v, want context.Canceled", err)
+	}
+	if err := <-joined; err != nil {
+		t.Fatalf("ExtractTextFromReader() error = %v, want the upload to continue from the second reader", err)
+	}
+
+	request, _ := server.LastRequest()
+	if !bytes.Equal(request.File, pdf) {
+		t.Errorf("server received %d bytes, want the %d byte PDF intact", len(request.File), len(pdf))
+	}
+	if n := countRequests(server, "/extract"); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+}
@@ -0,0 +1,316 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BalanceStrategy selects the endpoint each request is sent to.
+type BalanceStrategy int
+
+const (
+	// RoundRobin sends requests to each endpoint in turn.
+	RoundRobin BalanceStrategy = iota
+	// LeastOutstanding sends requests to the endpoint with the fewest
+	// requests in flight.
+	LeastOutstanding
+	// LatencyWeighted picks endpoints at random, favouring those with lower
+	// recent latency.
+	LatencyWeighted
+)
+
+// EndpointOptions configures WithEndpoints.
+type EndpointOptions struct {
+	Strategy BalanceStrategy
+	// EjectAfter is the number of consecutive network errors or 5xx
+	// responses after which an endpoint stops receiving requests. It
+	// defaults to 3.
+	EjectAfter int
+	// ProbeInterval is how often an ejected endpoint is probed with a
+	// health check; it is reinstated once a probe succeeds. It defaults to
+	// 10 seconds.
+	ProbeInterval time.Duration
+}
+
+// EndpointStatus reports the state of an endpoint.
+type EndpointStatus struct {
+	URL         string
+	Ejected     bool
+	Outstanding int
+	// Latency is a moving average of the endpoint's response times.
+	Latency             time.Duration
+	ConsecutiveFailures int
+}
+
+// WithEndpoints spreads requests over several instances of the service,
+// replacing the base URL passed to NewClient. Endpoints that keep failing
+// are ejected until a background health check succeeds, and retries are sent
+// to a different endpoint than the attempt that failed. Call Close to stop
+// the health checks.
+func WithEndpoints(urls []string, options EndpointOptions) ClientOption {
+	return func(c *Client) {
+		c.endpointURLs = urls
+		c.endpointOptions = options
+	}
+}
+
+// Endpoints returns the status of the client's endpoints, or nil if it was
+// not created with WithEndpoints.
+func (c *Client) Endpoints() []EndpointStatus {
+	if c.balancer == nil {
+		return nil
+	}
+
+	c.balancer.mu.Lock()
+	defer c.balancer.mu.Unlock()
+
+	statuses := make([]EndpointStatus, len(c.balancer.endpoints))
+	for i, ep := range c.balancer.endpoints {
+		statuses[i] = EndpointStatus{
+			URL:                 ep.url,
+			Ejected:             ep.ejected,
+			Outstanding:         ep.outstanding,
+			Latency:             time.Duration(ep.latency),
+			ConsecutiveFailures: ep.failures,
+		}
+	}
+	return statuses
+}
+
+type endpoint struct {
+	url         string
+	outstanding int
+	latency     float64 // moving average in nanoseconds, 0 until measured
+	failures    int
+	ejected     bool
+}
+
+type balancer struct {
+	options EndpointOptions
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+}
+
+func newBalancer(urls []string, options EndpointOptions) *balancer {
+	if options.EjectAfter <= 0 {
+		options.EjectAfter = 3
+	}
+	if options.ProbeInterval <= 0 {
+		options.ProbeInterval = 10 * time.Second
+	}
+
+	b := &balancer{options: options}
+	for _, u := range urls {
+		b.endpoints = append(b.endpoints, &endpoint{url: u})
+	}
+	return b
+}
+
+// pick chooses the endpoint for an attempt and counts it as outstanding.
+// Ejected endpoints and the endpoint of the previous failed attempt are
+// avoided unless no other endpoint is left.
+func (b *balancer) pick(previous *endpoint) *endpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	candidates := make([]*endpoint, 0, len(b.endpoints))
+	for _, ep := range b.endpoints {
+		if !ep.ejected && ep != previous {
+			candidates = append(candidates, ep)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, ep := range b.endpoints {
+			if !ep.ejected {
+				candidates = append(candidates, ep)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		// Every endpoint is ejected; keep trying rather than fail outright.
+		candidates = b.endpoints
+	}
+
+	var chosen *endpoint
+	switch b.options.Strategy {
+	case LeastOutstanding:
+		// Start at the round-robin position so ties are spread evenly.
+		for i := range candidates {
+			ep := candidates[(b.next+i)%len(candidates)]
+			if chosen == nil || ep.outstanding < chosen.outstanding {
+				chosen = ep
+			}
+		}
+		b.next++
+	case LatencyWeighted:
+		chosen = pickByLatency(candidates)
+	default:
+		chosen = candidates[b.next%len(candidates)]
+		b.next++
+	}
+
+	chosen.outstanding++
+	return chosen
+}
+
+// pickByLatency picks a candidate with probability inversely proportional
+// to its latency. Endpoints not measured yet count as the fastest.
+func pickByLatency(candidates []*endpoint) *endpoint {
+	fastest := 0.0
+	for _, ep := range candidates {
+		if ep.latency > 0 && (fastest == 0 || ep.latency < fastest) {
+			fastest = ep.latency
+		}
+	}
+	if fastest == 0 {
+		return candidates[rand.Intn(len(candidates))]
+	}
+
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, ep := range candidates {
+		latency := ep.latency
+		if latency == 0 {
+			latency = fastest
+		}
+		weights[i] = 1 / latency
+		total += weights[i]
+	}
+
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return candidates[i]
+		}
+		r -= w
+	}
+	return candidates[len(candidates)-1]
+}
+
+// done records the outcome of an attempt sent to ep. It reports whether the
+// endpoint was ejected as a result.
+func (b *balancer) done(ep *endpoint, latency time.Duration, err error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ep.outstanding--
+
+	if isEndpointFailure(err) {
+		ep.failures++
+		if !ep.ejected && ep.failures >= b.options.EjectAfter && b.available() > 1 {
+			ep.ejected = true
+			return true
+		}
+		return false
+	}
+
+	ep.failures = 0
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		const weight = 0.3
+		if ep.latency == 0 {
+			ep.latency = float64(latency)
+		} else {
+			ep.latency = weight*float64(latency) + (1-weight)*ep.latency
+		}
+	}
+	return false
+}
+
+// available returns the number of endpoints not ejected. b.mu must be held.
+func (b *balancer) available() int {
+	n := 0
+	for _, ep := range b.endpoints {
+		if !ep.ejected {
+			n++
+		}
+	}
+	return n
+}
+
+func (b *balancer) reinstate(ep *endpoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ep.ejected = false
+	ep.failures = 0
+}
+
+// isEndpointFailure reports whether err shows that an endpoint is unhealthy.
+func isEndpointFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.StatusCode >= 500
+	}
+	var netErr *NetworkError
+	return errors.As(err, &netErr)
+}
+
+// pickEndpoint returns the endpoint for an attempt of r and its base URL.
+// The endpoint is nil when the client has a single base URL.
+func (c *Client) pickEndpoint(r apiRequest, previous *endpoint) (*endpoint, string) {
+	if r.endpoint != "" {
+		return nil, r.endpoint
+	}
+	if c.balancer == nil {
+		return nil, c.BaseURL
+	}
+	ep := c.balancer.pick(previous)
+	return ep, ep.url
+}
+
+// endpointDone records the outcome of an attempt sent to ep, probing the
+// endpoint in the background if it was ejected.
+func (c *Client) endpointDone(ctx context.Context, ep *endpoint, latency time.Duration, err error) {
+	if ep == nil || !c.balancer.done(ep, latency, err) {
+		return
+	}
+
+	c.logger().LogAttrs(ctx, slog.LevelDebug, "pdfclient endpoint ejected",
+		slog.String("endpoint", ep.url),
+		slog.Any("error", err))
+	c.goBackground(func(ctx context.Context) { c.probe(ctx, ep) })
+}
+
+// probe health checks an ejected endpoint until it succeeds or closeCtx,
+// which is cancelled when the client is closed, is done.
+func (c *Client) probe(closeCtx context.Context, ep *endpoint) {
+	interval := c.balancer.options.ProbeInterval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closeCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(closeCtx, interval)
+		var health HealthResponse
+		err := c.do(ctx, apiRequest{
+			op:       OpHealthCheck,
+			method:   http.MethodGet,
+			path:     "/health",
+			endpoint: ep.url,
+		}, &health)
+		cancel()
+
+		if err == nil {
+			c.balancer.reinstate(ep)
+			c.logger().LogAttrs(context.Background(), slog.LevelDebug, "pdfclient endpoint reinstated",
+				slog.String("endpoint", ep.url))
+			return
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func newEndpointClient(t *testing.T, servers []*pdfclienttest.Server, options pdfclient.EndpointOptions, extra ...pdfclient.ClientOption) *pdfclient.Client {
+	t.Helper()
+	urls := make([]string, len(servers))
+	for i, server := range servers {
+		urls[i] = server.URL
+	}
+
+	client, err := pdfclient.NewClient("", append([]pdfclient.ClientOption{pdfclient.WithEndpoints(urls, options)}, extra...)...)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestEndpoints_RoundRobin(t *testing.T) {
+	servers := []*pdfclienttest.Server{pdfclienttest.NewServer(), pdfclienttest.NewServer(), pdfclienttest.NewServer()}
+	for _, server := range servers {
+		defer server.Close()
+	}
+
+	client := newEndpointClient(t, servers, pdfclient.EndpointOptions{})
+
+	for i := 0; i < 6; i++ {
+		health, err := client.HealthCheck(context.Background())
+		if err != nil {
+			t.Fatalf("HealthCheck() error = %v", err)
+		}
+		if want := servers[i%3].URL; health.Endpoint != want {
+			t.Errorf("HealthCheck() %d endpoint = %v, want %v", i, health.Endpoint, want)
+		}
+	}
+
+	for i, server := range servers {
+		if n := len(server.Requests()); n != 2 {
+			t.Errorf("server %d received %d requests, want %d", i, n, 2)
+		}
+	}
+}
+
+func TestEndpoints_LeastOutstanding(t *testing.T) {
+	release := make(chan struct{})
+	slow := pdfclienttest.NewServer()
+	defer slow.Close()
+	held := holdRequests(t, slow, release)
+	defer close(release)
+	fast := pdfclienttest.NewServer()
+	defer fast.Close()
+
+	client, err := pdfclient.NewClient("", pdfclient.WithEndpoints([]string{held.URL, fast.URL},
+		pdfclient.EndpointOptions{Strategy: pdfclient.LeastOutstanding}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		client.HealthCheck(context.Background())
+	}()
+	waitFor(t, "a request outstanding on the slow endpoint", func() bool {
+		return client.Endpoints()[0].Outstanding == 1
+	})
+
+	for i := 0; i < 3; i++ {
+		health, err := client.HealthCheck(context.Background())
+		if err != nil {
+			t.Fatalf("HealthCheck() error = %v", err)
+		}
+		if health.Endpoint != fast.URL {
+			t.Errorf("HealthCheck() endpoint = %v, want the idle endpoint %v", health.Endpoint, fast.URL)
+		}
+	}
+	release <- struct{}{}
+	wg.Wait()
+}
+
+func TestEndpoints_LatencyWeighted(t *testing.T) {
+	slow := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.Latency(30 * time.Millisecond)))
+	defer slow.Close()
+	fast := pdfclienttest.NewServer()
+	defer fast.Close()
+
+	client := newEndpointClient(t, []*pdfclienttest.Server{slow, fast},
+		pdfclient.EndpointOptions{Strategy: pdfclient.LatencyWeighted})
+
+	// Measure both endpoints.
+	for len(slow.Requests()) == 0 || len(fast.Requests()) == 0 {
+		client.HealthCheck(context.Background())
+	}
+	before := len(fast.Requests())
+
+	for i := 0; i < 20; i++ {
+		if _, err := client.HealthCheck(context.Background()); err != nil {
+			t.Fatalf("HealthCheck() error = %v", err)
+		}
+	}
+
+	if n := len(fast.Requests()) - before; n < 15 {
+		t.Errorf("fast endpoint received %d of 20 requests, want most of them", n)
+	}
+}
+
+func TestEndpoints_EjectionAndFailover(t *testing.T) {
+	failing := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.ServerError(503)))
+	defer failing.Close()
+	healthy := pdfclienttest.NewServer()
+	defer healthy.Close()
+
+	client := newEndpointClient(t, []*pdfclienttest.Server{failing, healthy},
+		pdfclient.EndpointOptions{EjectAfter: 2, ProbeInterval: 20 * time.Millisecond},
+		pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+
+	for i := 0; i < 6; i++ {
+		result, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+		if err != nil {
+			t.Fatalf("ExtractTextFromBytes() error = %v, want a retry on the healthy endpoint", err)
+		}
+		if result.Endpoint != healthy.URL {
+			t.Errorf("ExtractTextFromBytes() endpoint = %v, want %v", result.Endpoint, healthy.URL)
+		}
+	}
+
+	// The failing endpoint was ejected after two failures, so later calls
+	// did not reach it.
+	if n := countRequests(failing, "/extract"); n != 2 {
+		t.Errorf("failing endpoint received %d uploads, want %d", n, 2)
+	}
+	if status := client.Endpoints()[0]; !status.Ejected {
+		t.Errorf("Endpoints()[0] = %+v, want ejected", status)
+	}
+
+	// Once it recovers, a health check probe reinstates it.
+	failing.ClearFaults()
+	waitFor(t, "the recovered endpoint to be reinstated", func() bool {
+		return !client.Endpoints()[0].Ejected
+	})
+}
+
+func TestEndpoints_ErrorsReportEndpoint(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.ServerError(500)))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.HealthCheck(context.Background())
+	var clientErr *pdfclient.ClientError
+	if !errors.As(err, &clientErr) {
+		t.Fatalf("HealthCheck() error = %v, want *ClientError", err)
+	}
+	if clientErr.Endpoint != server.URL {
+		t.Errorf("ClientError.Endpoint = %v, want %v", clientErr.Endpoint, server.URL)
+	}
+
+	server.Close()
+	_, err = client.HealthCheck(context.Background())
+	var netErr *pdfclient.NetworkError
+	if !errors.As(err, &netErr) {
+		t.Fatalf("HealthCheck() error = %v, want *NetworkError", err)
+	}
+	if netErr.Endpoint != server.URL {
+		t.Errorf("NetworkError.Endpoint = %v, want %v", netErr.Endpoint, server.URL)
+	}
+}
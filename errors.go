@@ -0,0 +1,352 @@
+package pdfclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors describing the category of a failure. Errors returned by
+// Client match them with errors.Is:
+//
+//	if errors.Is(err, pdfclient.ErrInvalidPDF) { ... }
+var (
+	ErrInvalidPDF        = errors.New("invalid PDF")
+	ErrTooLarge          = errors.New("file too large")
+	ErrTimeout           = errors.New("timeout")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrGCSPermission     = errors.New("GCS permission denied")
+	ErrGCSNotFound       = errors.New("GCS object not found")
+	ErrServerUnavailable = errors.New("server unavailable")
+	ErrNetwork           = errors.New("network error")
+)
+
+// Machine-readable error codes sent by the service in the "code" field of an
+// error response. When a code is present it takes precedence over the HTTP
+// status and detail message in classifying the error.
+const (
+	CodeInvalidPDF         = "invalid_pdf"
+	CodeFileTooLarge       = "file_too_large"
+	CodeTimeout            = "timeout"
+	CodeUnauthorized       = "unauthorized"
+	CodeGCSPermission      = "gcs_permission_denied"
+	CodeGCSNotFound        = "gcs_not_found"
+	CodeServiceUnavailable = "service_unavailable"
+)
+
+// codeErrors maps error codes to the sentinel they match.
+var codeErrors = map[string]error{
+	CodeInvalidPDF:         ErrInvalidPDF,
+	CodeFileTooLarge:       ErrTooLarge,
+	CodeTimeout:            ErrTimeout,
+	CodeUnauthorized:       ErrUnauthorized,
+	CodeGCSPermission:      ErrGCSPermission,
+	CodeGCSNotFound:        ErrGCSNotFound,
+	CodeServiceUnavailable: ErrServerUnavailable,
+}
+
+// ClientError is returned as a *ClientError when the API responds with a
+// status other than 200 OK.
+type ClientError struct {
+	StatusCode int
+	Message    string
+	Detail     string
+
+	// Code is the machine-readable error code of the response, or "" if the
+	// service did not send one.
+	Code string
+
+	// FieldErrors holds the field-level errors of a FastAPI request
+	// validation failure (HTTP 422).
+	FieldErrors []FieldError
+
+	// RetryAfter is the delay requested by the server's Retry-After header,
+	// or zero if none was sent.
+	RetryAfter time.Duration
+
+	// Method and URL identify the request that failed.
+	Method string
+	URL    string
+	// Endpoint is the base URL of the service the request was sent to.
+	Endpoint string
+	// Header holds the response headers.
+	Header http.Header
+}
+
+func (e ClientError) Error() string {
+	if len(e.FieldErrors) > 0 {
+		return fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Detail)
+	}
+	if e.Detail != "" {
+		return fmt.Sprintf("API error (HTTP %d): %s - %s", e.StatusCode, e.Message, e.Detail)
+	}
+	return fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether the error belongs to the category of the sentinel
+// target, such as ErrInvalidPDF.
+func (e ClientError) Is(target error) bool {
+	switch target {
+	case ErrInvalidPDF:
+		return e.IsInvalidPDFError()
+	case ErrTooLarge:
+		return e.IsFileSizeError()
+	case ErrTimeout:
+		return e.IsTimeoutError()
+	case ErrUnauthorized:
+		return e.IsUnauthorizedError()
+	case ErrGCSPermission:
+		return e.IsGCSPermissionError()
+	case ErrGCSNotFound:
+		return e.IsGCSNotFoundError()
+	case ErrServerUnavailable:
+		return e.IsServerUnavailableError()
+	}
+	return false
+}
+
+// As lets errors.As fill a ClientError value as well as a *ClientError.
+func (e *ClientError) As(target any) bool {
+	if t, ok := target.(*ClientError); ok {
+		*t = *e
+		return true
+	}
+	return false
+}
+
+// hasCode reports whether the response carried a code, and if so whether it
+// maps to sentinel.
+func (e ClientError) hasCode(sentinel error) (matches, ok bool) {
+	if e.Code == "" {
+		return false, false
+	}
+	mapped, known := codeErrors[e.Code]
+	if !known {
+		return false, false
+	}
+	return mapped == sentinel, true
+}
+
+// IsInvalidPDFError returns true if the error is related to an invalid PDF
+func (e ClientError) IsInvalidPDFError() bool {
+	if matches, ok := e.hasCode(ErrInvalidPDF); ok {
+		return matches
+	}
+	return e.StatusCode == http.StatusBadRequest &&
+		(strings.Contains(e.Detail, "PDF file has syntax errors") ||
+			strings.Contains(e.Detail, "PDF file appears to be corrupted") ||
+			strings.Contains(e.Detail, "PDF file is incomplete or truncated") ||
+			strings.Contains(e.Detail, "Invalid PDF format") ||
+			strings.Contains(e.Detail, "file does not appear to be a valid PDF"))
+}
+
+// IsTimeoutError returns true if the error is a timeout error
+func (e ClientError) IsTimeoutError() bool {
+	if matches, ok := e.hasCode(ErrTimeout); ok {
+		return matches
+	}
+	return e.StatusCode == http.StatusRequestTimeout ||
+		strings.Contains(e.Detail, "timed out")
+}
+
+// IsFileSizeError returns true if the error is related to a file size limit
+func (e ClientError) IsFileSizeError() bool {
+	if matches, ok := e.hasCode(ErrTooLarge); ok {
+		return matches
+	}
+	return e.StatusCode == http.StatusRequestEntityTooLarge ||
+		strings.Contains(e.Detail, "File too large")
+}
+
+// IsUnauthorizedError returns true if the API key was missing or rejected
+func (e ClientError) IsUnauthorizedError() bool {
+	if matches, ok := e.hasCode(ErrUnauthorized); ok {
+		return matches
+	}
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// IsGCSPermissionError returns true if the error is related to GCS permissions
+func (e ClientError) IsGCSPermissionError() bool {
+	if matches, ok := e.hasCode(ErrGCSPermission); ok {
+		return matches
+	}
+	return e.StatusCode == http.StatusForbidden &&
+		strings.Contains(e.Detail, "Permission denied")
+}
+
+// IsGCSNotFoundError returns true if the error is related to GCS resource not found
+func (e ClientError) IsGCSNotFoundError() bool {
+	if matches, ok := e.hasCode(ErrGCSNotFound); ok {
+		return matches
+	}
+	return e.StatusCode == http.StatusNotFound &&
+		(strings.Contains(e.Detail, "not found") || strings.Contains(e.Detail, "does not exist"))
+}
+
+// IsServerUnavailableError returns true if the service or a gateway in front
+// of it is temporarily unable to handle the request
+func (e ClientError) IsServerUnavailableError() bool {
+	if matches, ok := e.hasCode(ErrServerUnavailable); ok {
+		return matches
+	}
+	return e.StatusCode == http.StatusBadGateway ||
+		e.StatusCode == http.StatusServiceUnavailable ||
+		e.StatusCode == http.StatusGatewayTimeout
+}
+
+// newClientError builds the error for a non-200 response with the given
+// body.
+func newClientError(req *http.Request, resp *http.Response, body []byte) *ClientError {
+	clientErr := &ClientError{
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Header:     resp.Header,
+	}
+
+	// Try to parse the error response as JSON. FastAPI sends the detail as
+	// a string, or as a list of field errors for validation failures; the
+	// service may also send it as an object with a code.
+	var apiError struct {
+		Detail json.RawMessage `json:"detail"`
+		Code   string          `json:"code"`
+	}
+	if err := json.Unmarshal(body, &apiError); err != nil {
+		return clientErr
+	}
+	clientErr.Code = apiError.Code
+
+	var detail string
+	var detailObject struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	var fieldErrors []FieldError
+	switch {
+	case json.Unmarshal(apiError.Detail, &detail) == nil:
+		clientErr.Detail = detail
+	case json.Unmarshal(apiError.Detail, &fieldErrors) == nil:
+		clientErr.FieldErrors = fieldErrors
+		clientErr.Detail = summarizeFieldErrors(fieldErrors)
+	case json.Unmarshal(apiError.Detail, &detailObject) == nil:
+		clientErr.Detail = detailObject.Message
+		if clientErr.Code == "" {
+			clientErr.Code = detailObject.Code
+		}
+	}
+
+	return clientErr
+}
+
+// FieldError is one entry of a FastAPI validation error, describing a
+// problem with a single request field.
+type FieldError struct {
+	// Loc is the location of the field, such as ["body", "method"]. List
+	// indices are rendered as decimal strings.
+	Loc  []string
+	Msg  string
+	Type string
+}
+
+// Field returns the dotted path of the field without its location prefix,
+// for example "method" for ["body", "method"].
+func (f FieldError) Field() string {
+	loc := f.Loc
+	if len(loc) > 1 {
+		switch loc[0] {
+		case "body", "query", "path", "header", "cookie":
+			loc = loc[1:]
+		}
+	}
+	return strings.Join(loc, ".")
+}
+
+func (f FieldError) String() string {
+	if field := f.Field(); field != "" {
+		return field + ": " + f.Msg
+	}
+	return f.Msg
+}
+
+func (f *FieldError) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Loc  []any  `json:"loc"`
+		Msg  string `json:"msg"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Msg == "" && raw.Type == "" {
+		return errors.New("not a validation error")
+	}
+
+	f.Msg = raw.Msg
+	f.Type = raw.Type
+	f.Loc = make([]string, len(raw.Loc))
+	for i, part := range raw.Loc {
+		switch v := part.(type) {
+		case string:
+			f.Loc[i] = v
+		case float64:
+			f.Loc[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			f.Loc[i] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+func summarizeFieldErrors(fieldErrors []FieldError) string {
+	parts := make([]string, len(fieldErrors))
+	for i, fieldErr := range fieldErrors {
+		parts[i] = fieldErr.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// NetworkError is returned when a request could not be sent or its response
+// could not be read. It matches ErrNetwork, and ErrTimeout if the failure
+// was a timeout.
+type NetworkError struct {
+	Method   string
+	URL      string
+	Endpoint string
+	Err      error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("error making request: %v", e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NetworkError) Is(target error) bool {
+	switch target {
+	case ErrNetwork:
+		return true
+	case ErrTimeout:
+		return e.Timeout()
+	}
+	return false
+}
+
+// Timeout reports whether the request failed because a deadline expired.
+func (e *NetworkError) Timeout() bool {
+	var netErr net.Error
+	if errors.As(e.Err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
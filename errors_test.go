@@ -0,0 +1,261 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestClientError_Sentinels(t *testing.T) {
+	tests := []struct {
+		name string
+		err  pdfclient.ClientError
+		want error
+	}{
+		{
+			name: "invalid PDF detail",
+			err:  pdfclient.ClientError{StatusCode: http.StatusBadRequest, Detail: "Invalid PDF format"},
+			want: pdfclient.ErrInvalidPDF,
+		},
+		{
+			name: "too large status",
+			err:  pdfclient.ClientError{StatusCode: http.StatusRequestEntityTooLarge},
+			want: pdfclient.ErrTooLarge,
+		},
+		{
+			name: "timeout status",
+			err:  pdfclient.ClientError{StatusCode: http.StatusRequestTimeout},
+			want: pdfclient.ErrTimeout,
+		},
+		{
+			name: "unauthorized status",
+			err:  pdfclient.ClientError{StatusCode: http.StatusUnauthorized},
+			want: pdfclient.ErrUnauthorized,
+		},
+		{
+			name: "GCS permission detail",
+			err:  pdfclient.ClientError{StatusCode: http.StatusForbidden, Detail: "Permission denied accessing gs://b/f.pdf"},
+			want: pdfclient.ErrGCSPermission,
+		},
+		{
+			name: "GCS not found detail",
+			err:  pdfclient.ClientError{StatusCode: http.StatusNotFound, Detail: "Blob does not exist: gs://b/f.pdf"},
+			want: pdfclient.ErrGCSNotFound,
+		},
+		{
+			name: "service unavailable status",
+			err:  pdfclient.ClientError{StatusCode: http.StatusServiceUnavailable},
+			want: pdfclient.ErrServerUnavailable,
+		},
+		{
+			name: "code overrides reworded detail",
+			err:  pdfclient.ClientError{StatusCode: http.StatusBadRequest, Detail: "This is not a PDF", Code: pdfclient.CodeInvalidPDF},
+			want: pdfclient.ErrInvalidPDF,
+		},
+	}
+
+	sentinels := []error{
+		pdfclient.ErrInvalidPDF, pdfclient.ErrTooLarge, pdfclient.ErrTimeout, pdfclient.ErrUnauthorized,
+		pdfclient.ErrGCSPermission, pdfclient.ErrGCSNotFound, pdfclient.ErrServerUnavailable, pdfclient.ErrNetwork,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error = &tt.err
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, sentinel, got)
+				}
+			}
+		})
+	}
+}
+
+func TestClientError_ParsesCodeAndRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   string
+		wantDetail string
+	}{
+		{
+			name:       "top-level code",
+			body:       `{"detail":"Object is missing","code":"gcs_not_found"}`,
+			wantCode:   pdfclient.CodeGCSNotFound,
+			wantDetail: "Object is missing",
+		},
+		{
+			name:       "detail object",
+			body:       `{"detail":{"code":"gcs_not_found","message":"Object is missing"}}`,
+			wantCode:   pdfclient.CodeGCSNotFound,
+			wantDetail: "Object is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-Id", "abc123")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := pdfclient.NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			_, err = client.ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{InputGCSURL: "gs://b/f.pdf"})
+
+			var clientErr *pdfclient.ClientError
+			if !errors.As(err, &clientErr) {
+				t.Fatalf("ExtractTextFromGCS() error = %v, want *ClientError", err)
+			}
+
+			if clientErr.Code != tt.wantCode || clientErr.Detail != tt.wantDetail {
+				t.Errorf("ClientError code = %q, detail = %q, want %q, %q", clientErr.Code, clientErr.Detail, tt.wantCode, tt.wantDetail)
+			}
+
+			if !errors.Is(err, pdfclient.ErrGCSNotFound) {
+				t.Errorf("errors.Is(%v, ErrGCSNotFound) = false", err)
+			}
+
+			if clientErr.Method != http.MethodPost || clientErr.URL != server.URL+"/extract-from-gcs" {
+				t.Errorf("ClientError request = %s %s", clientErr.Method, clientErr.URL)
+			}
+
+			if clientErr.Header.Get("X-Request-Id") != "abc123" {
+				t.Errorf("ClientError header X-Request-Id = %q", clientErr.Header.Get("X-Request-Id"))
+			}
+		})
+	}
+}
+
+func TestNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.HealthCheck(context.Background())
+	if !errors.Is(err, pdfclient.ErrNetwork) || !errors.Is(err, pdfclient.ErrTimeout) {
+		t.Errorf("HealthCheck() error = %v, want ErrNetwork and ErrTimeout", err)
+	}
+
+	var networkErr *pdfclient.NetworkError
+	if !errors.As(err, &networkErr) || networkErr.URL != server.URL+"/health" {
+		t.Errorf("HealthCheck() error = %v, want *NetworkError for /health", err)
+	}
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	client, err = pdfclient.NewClient(closed.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.HealthCheck(context.Background())
+	if !errors.Is(err, pdfclient.ErrNetwork) || errors.Is(err, pdfclient.ErrTimeout) {
+		t.Errorf("HealthCheck() error = %v, want ErrNetwork only", err)
+	}
+}
+
+func TestNetworkError_ResponseBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		newServer func() *httptest.Server
+	}{
+		{"reset connection", func() *httptest.Server {
+			return pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.ResetConnection())).Server
+		}},
+		{"truncated body", func() *httptest.Server {
+			return pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.TruncatedBody())).Server
+		}},
+		{"truncated error body", func() *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", "100")
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"detail":`))
+			}))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := tt.newServer()
+			defer server.Close()
+
+			client, err := pdfclient.NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+			if !errors.Is(err, pdfclient.ErrNetwork) {
+				t.Errorf("ExtractTextFromBytes() error = %v, want ErrNetwork", err)
+			}
+			var networkErr *pdfclient.NetworkError
+			if !errors.As(err, &networkErr) || networkErr.URL != server.URL+"/extract" {
+				t.Errorf("ExtractTextFromBytes() error = %v, want *NetworkError for /extract", err)
+			}
+		})
+	}
+}
+
+func TestClientError_FieldErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"detail":[
+			{"loc":["body","method"],"msg":"value is not a valid enumeration member","type":"type_error.enum"},
+			{"loc":["body","pages",0],"msg":"value is not a valid integer","type":"type_error.integer"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{
+		InputGCSURL: "gs://b/f.pdf",
+		Method:      "ocr",
+	})
+
+	var clientErr *pdfclient.ClientError
+	if !errors.As(err, &clientErr) {
+		t.Fatalf("ExtractTextFromGCS() error = %v, want *ClientError", err)
+	}
+
+	if len(clientErr.FieldErrors) != 2 {
+		t.Fatalf("ClientError.FieldErrors = %v, want 2 entries", clientErr.FieldErrors)
+	}
+
+	first := clientErr.FieldErrors[0]
+	if first.Field() != "method" || first.Type != "type_error.enum" {
+		t.Errorf("FieldErrors[0] = %+v", first)
+	}
+
+	if got := clientErr.FieldErrors[1].Field(); got != "pages.0" {
+		t.Errorf("FieldErrors[1].Field() = %q, want %q", got, "pages.0")
+	}
+
+	want := "API error (HTTP 422): method: value is not a valid enumeration member; pages.0: value is not a valid integer"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -74,12 +75,10 @@ func main() {
 	result, err := client.ExtractTextFromGCS(context.Background(), request)
 	if err != nil {
 		// Check for specific error types
-		if clientErr, ok := err.(pdfclient.ClientError); ok {
-			if clientErr.IsGCSPermissionError() {
-				log.Fatalf("GCS Permission Error: %v\nMake sure the service account has proper GCS permissions.", err)
-			} else if clientErr.IsGCSNotFoundError() {
-				log.Fatalf("GCS Not Found Error: %v\nCheck that the GCS URL is correct and the file exists.", err)
-			}
+		if errors.Is(err, pdfclient.ErrGCSPermission) {
+			log.Fatalf("GCS Permission Error: %v\nMake sure the service account has proper GCS permissions.", err)
+		} else if errors.Is(err, pdfclient.ErrGCSNotFound) {
+			log.Fatalf("GCS Not Found Error: %v\nCheck that the GCS URL is correct and the file exists.", err)
 		}
 		log.Fatalf("Text extraction failed: %v", err)
 	}
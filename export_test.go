@@ -0,0 +1,31 @@
+package pdfclient
+
+// FlightWaiters returns the number of callers waiting for shared
+// extractions, so tests can tell when a call has joined one.
+func FlightWaiters(c *Client) int {
+	if c.flights == nil {
+		return 0
+	}
+	c.flights.mu.Lock()
+	defer c.flights.mu.Unlock()
+
+	n := 0
+	for _, f := range c.flights.calls {
+		n += f.waiters
+	}
+	return n
+}
+
+// QueuedCalls returns the number of calls waiting for WithMaxConcurrent or
+// WithMaxInFlightBytes.
+func QueuedCalls(c *Client) int {
+	n := 0
+	for _, s := range []*semaphore{c.concurrency, c.inFlightBytes} {
+		if s != nil {
+			s.mu.Lock()
+			n += s.waiters.Len()
+			s.mu.Unlock()
+		}
+	}
+	return n
+}
@@ -0,0 +1,181 @@
+package pdfclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WaitUntilHealthy polls the service's health check every interval until it
+// reports an ok status, for example while a service that scales to zero is
+// starting. It returns the last health response, or the context's error
+// wrapped with the last failed check if ctx is done first. An interval of zero
+// polls every second.
+func (c *Client) WaitUntilHealthy(ctx context.Context, interval time.Duration) (*HealthResponse, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	var lastErr error
+	for {
+		health, err := c.checkHealth(ctx)
+		if err == nil {
+			return health, nil
+		}
+		if ctx.Err() == nil {
+			lastErr = err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if lastErr == nil {
+				return nil, fmt.Errorf("error waiting for service to become healthy: %w", ctx.Err())
+			}
+			return nil, fmt.Errorf("error waiting for service to become healthy: %w (last error: %v)", ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+	}
+}
+
+// checkHealth runs a health check, treating a status other than ok as an
+// error.
+func (c *Client) checkHealth(ctx context.Context) (*HealthResponse, error) {
+	health, err := c.HealthCheck(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if health.Status != "ok" {
+		return nil, fmt.Errorf("service reported status %q", health.Status)
+	}
+	return health, nil
+}
+
+// HealthStatus is the state of the service as seen by the health monitor.
+type HealthStatus struct {
+	Healthy bool
+	// Version is the version reported by the last successful check.
+	Version string
+	// LastError is the error of the last check, or nil if it succeeded.
+	LastError error
+	LastCheck time.Time
+	// Since is when Healthy last changed.
+	Since time.Time
+}
+
+// HealthMonitor configures WithHealthMonitor.
+type HealthMonitor struct {
+	// Interval is the time between health checks. It defaults to 30
+	// seconds.
+	Interval time.Duration
+	// OnChange, if set, is called from the monitor's goroutine after the
+	// first check and whenever the service becomes healthy or unhealthy or
+	// reports a different version.
+	OnChange func(HealthStatus)
+}
+
+// WithHealthMonitor checks the service's health in the background, starting
+// when the client is created and stopping when it is closed. The result is
+// available from Health and Healthy, so work can be held until the service
+// is up. A successful check also refreshes the cached server version used by
+// CacheOptions.MatchServerVersion, WithServerVersionConstraint and
+// Capabilities.
+func WithHealthMonitor(config HealthMonitor) ClientOption {
+	return func(c *Client) {
+		if config.Interval <= 0 {
+			config.Interval = 30 * time.Second
+		}
+		c.monitor = &healthMonitor{config: config}
+	}
+}
+
+// Health returns the state recorded by the health monitor. Without
+// WithHealthMonitor it returns a zero HealthStatus.
+func (c *Client) Health() HealthStatus {
+	if c.monitor == nil {
+		return HealthStatus{}
+	}
+	c.monitor.mu.Lock()
+	defer c.monitor.mu.Unlock()
+	return c.monitor.status
+}
+
+// Healthy reports whether the last check of the health monitor succeeded.
+// It is false until the first check completes, and always true without
+// WithHealthMonitor.
+func (c *Client) Healthy() bool {
+	if c.monitor == nil {
+		return true
+	}
+	return c.Health().Healthy
+}
+
+type healthMonitor struct {
+	config HealthMonitor
+
+	mu     sync.Mutex
+	status HealthStatus
+}
+
+// record updates the status with the outcome of a check and reports whether
+// it changed.
+func (m *healthMonitor) record(health *HealthResponse, err error, now time.Time) (HealthStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous := m.status
+	m.status.LastCheck = now
+	m.status.LastError = err
+	m.status.Healthy = err == nil
+	if health != nil {
+		m.status.Version = health.Version
+	}
+	first := previous.LastCheck.IsZero()
+	if first || m.status.Healthy != previous.Healthy {
+		m.status.Since = now
+	}
+
+	changed := first || m.status.Healthy != previous.Healthy || m.status.Version != previous.Version
+	return m.status, changed
+}
+
+// monitorHealth runs the health monitor until ctx, which is cancelled when
+// the client is closed, is done.
+func (c *Client) monitorHealth(closeCtx context.Context) {
+	interval := c.monitor.config.Interval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(closeCtx, interval)
+		health, err := c.checkHealth(ctx)
+		cancel()
+		if closeCtx.Err() != nil {
+			return
+		}
+
+		if err == nil && health.Version != "" {
+			c.setVersion(health.Version)
+		}
+
+		status, changed := c.monitor.record(health, err, time.Now())
+		if changed {
+			c.logger().LogAttrs(context.Background(), slog.LevelDebug, "pdfclient health changed",
+				slog.Bool("healthy", status.Healthy),
+				slog.String("version", status.Version),
+				slog.Any("error", status.LastError))
+			if c.monitor.config.OnChange != nil {
+				c.monitor.config.OnChange(status)
+			}
+		}
+
+		select {
+		case <-closeCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestWaitUntilHealthy(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithVersion("1.2.0"),
+		pdfclienttest.WithFault(pdfclienttest.ServerError(503), pdfclienttest.OnPath("/health"), pdfclienttest.OnFirst(3)))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	health, err := client.WaitUntilHealthy(context.Background(), 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitUntilHealthy() error = %v", err)
+	}
+	if health.Version != "1.2.0" {
+		t.Errorf("WaitUntilHealthy() version = %v, want %v", health.Version, "1.2.0")
+	}
+	if n := countRequests(server, "/health"); n != 4 {
+		t.Errorf("server received %d health checks, want %d", n, 4)
+	}
+}
+
+func TestWaitUntilHealthy_Timeout(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.ServerError(503)))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.WaitUntilHealthy(ctx, 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitUntilHealthy() error = %v, want context.DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "503") {
+		t.Errorf("WaitUntilHealthy() error = %v, want it to include the last failure", err)
+	}
+}
+
+func TestHealthMonitor(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithVersion("1.2.0"),
+		pdfclienttest.WithFault(pdfclienttest.ServerError(503), pdfclienttest.OnPath("/health"), pdfclienttest.OnFirst(2)))
+	defer server.Close()
+
+	changes := make(chan pdfclient.HealthStatus, 10)
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithHealthMonitor(pdfclient.HealthMonitor{
+		Interval: 10 * time.Millisecond,
+		OnChange: func(status pdfclient.HealthStatus) { changes <- status },
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	var got []pdfclient.HealthStatus
+	for len(got) < 2 {
+		select {
+		case status := <-changes:
+			got = append(got, status)
+		case <-time.After(time.Second):
+			t.Fatalf("OnChange called with %+v, want an unhealthy and then a healthy status", got)
+		}
+	}
+
+	if got[0].Healthy || got[0].LastError == nil {
+		t.Errorf("first status = %+v, want unhealthy with an error", got[0])
+	}
+	if !got[1].Healthy || got[1].LastError != nil || got[1].Version != "1.2.0" {
+		t.Errorf("second status = %+v, want healthy at version 1.2.0", got[1])
+	}
+	if !client.Healthy() {
+		t.Errorf("Healthy() = false, want true")
+	}
+	if health := client.Health(); health.Since.After(health.LastCheck) {
+		t.Errorf("Health() = %+v, want Since no later than LastCheck", health)
+	}
+}
+
+func TestHealthMonitor_Close(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithHealthMonitor(pdfclient.HealthMonitor{
+		Interval: 5 * time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	checks := func() int64 {
+		return client.Stats().Operations[pdfclient.OpHealthCheck].Requests
+	}
+	waitFor(t, "the monitor to check the service", func() bool { return checks() > 0 })
+
+	// Close waits for the monitor to stop, so no check starts after it.
+	client.Close()
+	before := checks()
+	time.Sleep(20 * time.Millisecond)
+
+	if n := checks(); n != before {
+		t.Errorf("client made %d health checks after Close, want %d", n, before)
+	}
+}
+
+func TestHealthy_WithoutMonitor(t *testing.T) {
+	client, err := pdfclient.NewClient("http://localhost:8000")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if !client.Healthy() {
+		t.Errorf("Healthy() = false, want true without a health monitor")
+	}
+}
@@ -0,0 +1,36 @@
+package pdfclient
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mhpenta/pypdftotext-client/pdfmeta"
+)
+
+// Inspect reads the metadata of the PDF file at filePath locally, without
+// calling the service: its page count, version, encryption, tagged-PDF
+// status and document information. Files that cannot be read as PDFs return
+// the same errors as Preflight.
+func (c *Client) Inspect(filePath string) (*pdfmeta.Metadata, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer func(file *os.File) {
+		err = file.Close()
+		if err != nil {
+			c.logger().Error("Failed to close file", "error", err)
+		}
+	}(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading file size: %w", err)
+	}
+
+	meta, err := pdfmeta.Read(file, info.Size())
+	if err != nil {
+		return nil, preflightError(err)
+	}
+	return meta, nil
+}
@@ -0,0 +1,34 @@
+package pdfclient_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+func TestInspect(t *testing.T) {
+	// Inspect reads the file locally, so the client needs no server.
+	client, err := pdfclient.NewClient("http://127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	meta, err := client.Inspect("fixtures/example.pdf")
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if meta.PageCount != 15 || meta.Version != "1.7" || !meta.Tagged {
+		t.Errorf("Inspect() = %+v, want 15 pages, version 1.7, tagged", meta)
+	}
+
+	path := filepath.Join(t.TempDir(), "error.pdf")
+	if err := os.WriteFile(path, []byte("<html><body>Forbidden</body></html>"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := client.Inspect(path); !errors.Is(err, pdfclient.ErrInvalidPDF) {
+		t.Errorf("Inspect() error = %v, want ErrInvalidPDF", err)
+	}
+}
@@ -0,0 +1,212 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxDecodedSize bounds the size of decoded stream data, so a small stream
+// cannot expand without limit.
+const maxDecodedSize = 256 << 20
+
+// decode applies the filters named in the stream's dictionary. Filters
+// other than FlateDecode, ASCIIHexDecode and ASCII85Decode are not
+// supported.
+func decode(s *Stream, resolve func(Object) (Object, error)) ([]byte, error) {
+	filters, err := resolve(s.Dict["Filter"])
+	if err != nil {
+		return nil, err
+	}
+	params, err := resolve(s.Dict["DecodeParms"])
+	if err != nil {
+		return nil, err
+	}
+
+	var names []Object
+	var paramList []Object
+	switch f := filters.(type) {
+	case nil:
+		return s.Data, nil
+	case Name:
+		names = []Object{f}
+		paramList = []Object{params}
+	case Array:
+		names = f
+		if p, ok := params.(Array); ok {
+			paramList = p
+		}
+	default:
+		return nil, corruptf("invalid stream filter %v", filters)
+	}
+
+	data := s.Data
+	for i, name := range names {
+		var p Dict
+		if i < len(paramList) {
+			if obj, err := resolve(paramList[i]); err == nil {
+				p, _ = obj.(Dict)
+			}
+		}
+
+		switch name {
+		case Name("FlateDecode"), Name("Fl"):
+			data, err = inflate(data)
+			if err == nil {
+				data, err = unpredict(data, p, resolve)
+			}
+		case Name("ASCIIHexDecode"), Name("AHx"):
+			data, err = decodeASCIIHex(data)
+		case Name("ASCII85Decode"), Name("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("unsupported stream filter %v", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, corruptf("invalid compressed stream: %v", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(io.LimitReader(zr, maxDecodedSize+1))
+	if len(out) > maxDecodedSize {
+		return nil, corruptf("compressed stream is too large")
+	}
+	// Streams cut short or missing their checksum are common; keep what
+	// could be decompressed.
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && len(out) == 0 {
+		return nil, corruptf("invalid compressed stream: %v", err)
+	}
+	return out, nil
+}
+
+// unpredict reverses the PNG predictors used by cross-reference and object
+// streams.
+func unpredict(data []byte, params Dict, resolve func(Object) (Object, error)) ([]byte, error) {
+	param := func(key Name, def int64) int64 {
+		obj, err := resolve(params[key])
+		if err != nil {
+			return def
+		}
+		if v, ok := Int(obj); ok {
+			return v
+		}
+		return def
+	}
+
+	predictor := param("Predictor", 1)
+	if predictor == 1 {
+		return data, nil
+	}
+	if predictor < 10 {
+		return nil, fmt.Errorf("unsupported predictor %d", predictor)
+	}
+
+	colors := param("Colors", 1)
+	bits := param("BitsPerComponent", 8)
+	columns := param("Columns", 1)
+	if colors < 1 || bits < 1 || columns < 1 || colors*bits*columns > 1<<20 {
+		return nil, corruptf("invalid predictor parameters")
+	}
+	bpp := int((colors*bits + 7) / 8)
+	rowLen := int((colors*bits*columns + 7) / 8)
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for len(data) > 0 {
+		filter := data[0]
+		data = data[1:]
+		row := make([]byte, rowLen)
+		n := copy(row, data)
+		data = data[n:]
+
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+			switch filter {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, corruptf("invalid PNG predictor %d", filter)
+			}
+		}
+		out = append(out, row[:n]...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	var digits []byte
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if !isSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	if _, err := hex.Decode(out, digits); err != nil {
+		return nil, corruptf("invalid ASCIIHex stream: %v", err)
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	out := make([]byte, 4*len(data)+4) // z expands to four bytes
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, corruptf("invalid ASCII85 stream: %v", err)
+	}
+	return out[:n], nil
+}
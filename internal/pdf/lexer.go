@@ -0,0 +1,396 @@
+package pdf
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// maxDepth bounds the nesting of arrays and dictionaries, so malformed
+// files cannot exhaust the stack.
+const maxDepth = 100
+
+// lexer reads objects from a file through a small window, so large files
+// are never read into memory whole.
+type lexer struct {
+	r    io.ReaderAt
+	size int64
+	pos  int64
+	buf  []byte
+	off  int64 // file offset of buf[0]
+}
+
+func newLexer(r io.ReaderAt, size, pos int64) *lexer {
+	return &lexer{r: r, size: size, pos: pos}
+}
+
+// peek returns the byte at the current position.
+func (l *lexer) peek() (byte, bool) {
+	if l.pos < 0 || l.pos >= l.size {
+		return 0, false
+	}
+	if l.pos < l.off || l.pos >= l.off+int64(len(l.buf)) {
+		n := min(int64(4096), l.size-l.pos)
+		if cap(l.buf) < int(n) {
+			l.buf = make([]byte, n)
+		}
+		l.buf = l.buf[:n]
+		read, _ := l.r.ReadAt(l.buf, l.pos)
+		l.buf = l.buf[:read]
+		l.off = l.pos
+		if read == 0 {
+			return 0, false
+		}
+	}
+	return l.buf[l.pos-l.off], true
+}
+
+func (l *lexer) next() (byte, bool) {
+	c, ok := l.peek()
+	if ok {
+		l.pos++
+	}
+	return c, ok
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace and comments.
+func (l *lexer) skipSpace() {
+	for {
+		c, ok := l.peek()
+		if !ok {
+			return
+		}
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for {
+				c, ok := l.next()
+				if !ok || c == '\n' || c == '\r' {
+					break
+				}
+			}
+		default:
+			return
+		}
+	}
+}
+
+// regular reads a run of regular characters.
+func (l *lexer) regular() []byte {
+	var tok []byte
+	for {
+		c, ok := l.peek()
+		if !ok || isSpace(c) || isDelimiter(c) {
+			return tok
+		}
+		tok = append(tok, c)
+		l.pos++
+	}
+}
+
+// keyword reads the next token and reports whether it is the keyword want.
+func (l *lexer) keyword(want string) bool {
+	l.skipSpace()
+	start := l.pos
+	if string(l.regular()) == want {
+		return true
+	}
+	l.pos = start
+	return false
+}
+
+// integer reads the next token as a non-negative integer.
+func (l *lexer) integer() (int64, bool) {
+	l.skipSpace()
+	start := l.pos
+	tok := l.regular()
+	n, err := strconv.ParseInt(string(tok), 10, 64)
+	if err != nil || n < 0 {
+		l.pos = start
+		return 0, false
+	}
+	return n, true
+}
+
+// object reads the next object. Integers followed by a generation and R
+// are read as references. Bare keywords are returned as keyword values.
+func (l *lexer) object() (Object, error) {
+	return l.parse(0)
+}
+
+func (l *lexer) parse(depth int) (Object, error) {
+	if depth > maxDepth {
+		return nil, corruptf("objects nested too deeply at offset %d", l.pos)
+	}
+
+	l.skipSpace()
+	start := l.pos
+	c, ok := l.next()
+	if !ok {
+		return nil, corruptf("unexpected end of file at offset %d", start)
+	}
+
+	switch c {
+	case '/':
+		return l.name(), nil
+	case '(':
+		return l.literalString()
+	case '<':
+		if c, _ := l.peek(); c == '<' {
+			l.pos++
+			return l.dict(depth)
+		}
+		return l.hexString()
+	case '[':
+		return l.array(depth)
+	case ']', '>', ')', '{', '}':
+		return nil, corruptf("unexpected %q at offset %d", c, start)
+	}
+
+	l.pos = start
+	tok := l.regular()
+	switch string(tok) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	if isNumber(tok) {
+		if n, err := strconv.ParseInt(string(tok), 10, 64); err == nil {
+			if n >= 0 {
+				if ref, ok := l.reference(n); ok {
+					return ref, nil
+				}
+			}
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(string(tok), 64)
+		if err != nil {
+			return 0.0, nil
+		}
+		return f, nil
+	}
+
+	if len(tok) == 0 {
+		return nil, corruptf("unexpected %q at offset %d", c, start)
+	}
+	return keyword(tok), nil
+}
+
+func isNumber(tok []byte) bool {
+	if len(tok) == 0 {
+		return false
+	}
+	digits := 0
+	for i, c := range tok {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.':
+		case (c == '+' || c == '-') && i == 0:
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// reference completes a reference whose object number num has been read.
+func (l *lexer) reference(num int64) (Ref, bool) {
+	start := l.pos
+	gen, ok := l.integer()
+	if ok && l.keyword("R") {
+		return Ref{Num: int(num), Gen: int(gen)}, true
+	}
+	l.pos = start
+	return Ref{}, false
+}
+
+func (l *lexer) name() Name {
+	tok := l.regular()
+	if bytes.IndexByte(tok, '#') < 0 {
+		return Name(tok)
+	}
+
+	var b []byte
+	for i := 0; i < len(tok); i++ {
+		if tok[i] == '#' && i+2 < len(tok) {
+			if v, err := strconv.ParseUint(string(tok[i+1:i+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				i += 2
+				continue
+			}
+		}
+		b = append(b, tok[i])
+	}
+	return Name(b)
+}
+
+func (l *lexer) literalString() (Object, error) {
+	var b []byte
+	nesting := 0
+	for {
+		c, ok := l.next()
+		if !ok {
+			return nil, corruptf("unterminated string")
+		}
+		switch c {
+		case '(':
+			nesting++
+		case ')':
+			if nesting == 0 {
+				return String(b), nil
+			}
+			nesting--
+		case '\\':
+			c, ok = l.next()
+			if !ok {
+				return nil, corruptf("unterminated string")
+			}
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// A backslash at the end of a line continues the string.
+				if next, _ := l.peek(); next == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				v := int(c - '0')
+				for i := 0; i < 2; i++ {
+					d, ok := l.peek()
+					if !ok || d < '0' || d > '7' {
+						break
+					}
+					v = v*8 + int(d-'0')
+					l.pos++
+				}
+				c = byte(v)
+			}
+		}
+		b = append(b, c)
+	}
+}
+
+func (l *lexer) hexString() (Object, error) {
+	var b []byte
+	var digit byte
+	odd := false
+	for {
+		c, ok := l.next()
+		if !ok {
+			return nil, corruptf("unterminated hex string")
+		}
+		var v byte
+		switch {
+		case c == '>':
+			if odd {
+				b = append(b, digit<<4)
+			}
+			return String(b), nil
+		case isSpace(c):
+			continue
+		case c >= '0' && c <= '9':
+			v = c - '0'
+		case c >= 'a' && c <= 'f':
+			v = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			v = c - 'A' + 10
+		default:
+			return nil, corruptf("invalid character %q in hex string", c)
+		}
+		if odd {
+			b = append(b, digit<<4|v)
+		} else {
+			digit = v
+		}
+		odd = !odd
+	}
+}
+
+func (l *lexer) array(depth int) (Object, error) {
+	arr := Array{}
+	for {
+		l.skipSpace()
+		c, ok := l.peek()
+		if !ok {
+			return nil, corruptf("unterminated array")
+		}
+		if c == ']' {
+			l.pos++
+			return arr, nil
+		}
+		obj, err := l.parse(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := obj.(keyword); ok {
+			return nil, corruptf("unexpected %q in array", obj)
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (l *lexer) dict(depth int) (Object, error) {
+	d := Dict{}
+	for {
+		l.skipSpace()
+		c, ok := l.next()
+		if !ok {
+			return nil, corruptf("unterminated dictionary")
+		}
+		if c == '>' {
+			if c, _ := l.peek(); c == '>' {
+				l.pos++
+			}
+			return d, nil
+		}
+		if c != '/' {
+			return nil, corruptf("dictionary key is not a name at offset %d", l.pos-1)
+		}
+		key := l.name()
+
+		value, err := l.parse(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := value.(keyword); ok {
+			return nil, corruptf("unexpected %q in dictionary", value)
+		}
+		// A null value is the same as an absent entry.
+		if value != nil {
+			d[key] = value
+		}
+	}
+}
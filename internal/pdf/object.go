@@ -0,0 +1,85 @@
+// Package pdf reads the object structure of PDF files: the cross-reference
+// tables and streams, the trailer and the indirect objects they locate,
+// including objects stored in object streams. It does not interpret page
+// content.
+package pdf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned when a file cannot be read as a PDF.
+var (
+	// ErrNotPDF is returned for files that do not start with a PDF header.
+	ErrNotPDF = errors.New("not a PDF file")
+	// ErrTruncated is returned for files that end before the end-of-file
+	// marker.
+	ErrTruncated = errors.New("PDF file is truncated")
+	// ErrCorrupt is returned, wrapped with a description, for files whose
+	// structure cannot be read.
+	ErrCorrupt = errors.New("corrupt PDF")
+	// ErrEncrypted is returned when reading encrypted stream data.
+	ErrEncrypted = errors.New("PDF is encrypted")
+)
+
+func corruptf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrCorrupt, fmt.Sprintf(format, args...))
+}
+
+// Object is a PDF object: nil for null, bool, int64 for integers, float64
+// for reals, String, Name, Array, Dict, *Stream or Ref.
+type Object any
+
+// Name is a PDF name, without the leading slash.
+type Name string
+
+// String is a PDF string, with escapes decoded.
+type String string
+
+// Array is a PDF array.
+type Array []Object
+
+// Dict is a PDF dictionary.
+type Dict map[Name]Object
+
+// Ref is a reference to an indirect object.
+type Ref struct {
+	Num int
+	Gen int
+}
+
+// Stream is a stream object. Data holds the stream's bytes as stored in the
+// file, before any filters are decoded.
+type Stream struct {
+	Dict Dict
+	Data []byte
+}
+
+// keyword is a bare token such as obj, endobj, stream or R.
+type keyword string
+
+// Int returns the value of an integer object. Reals with an integral value
+// are accepted, as some writers produce them for counts and offsets.
+func Int(obj Object) (int64, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return v, true
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// Number returns the value of an integer or real object.
+func Number(obj Object) (float64, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,103 @@
+package pdf
+
+// Page is a page of a document.
+type Page struct {
+	// Ref refers to the page object. It is the zero Ref for pages stored
+	// directly in their parent's /Kids array.
+	Ref Ref
+	// Dict is a copy of the page dictionary with the attributes inherited
+	// from the page tree added.
+	Dict Dict
+}
+
+// inheritable are the page attributes that may be set on an ancestor in the
+// page tree instead of the page itself.
+var inheritable = []Name{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// Pages returns the pages of the document in order.
+func (r *Reader) Pages() ([]Page, error) {
+	if r.pages != nil {
+		return r.pages, nil
+	}
+
+	catalog, err := r.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	tree := map[int]bool{}
+	pages := []Page{}
+	if err := r.walkPages(catalog["Pages"], Dict{}, tree, 0, &pages); err != nil {
+		return nil, err
+	}
+	r.pages = pages
+	r.pageTree = tree
+	return pages, nil
+}
+
+// walkPages appends the pages below node to pages, recording the numbers of
+// the objects in the tree in tree.
+func (r *Reader) walkPages(node Object, inherited Dict, tree map[int]bool, depth int, pages *[]Page) error {
+	if depth > maxDepth {
+		return corruptf("page tree nested too deeply")
+	}
+
+	ref, isRef := node.(Ref)
+	if isRef {
+		if tree[ref.Num] {
+			return corruptf("page tree contains a cycle")
+		}
+		tree[ref.Num] = true
+	}
+	obj, err := r.Resolve(node)
+	if err != nil {
+		return err
+	}
+	d, ok := obj.(Dict)
+	if !ok {
+		if depth == 0 {
+			return corruptf("missing page tree")
+		}
+		return nil
+	}
+
+	kids, err := r.Resolve(d["Kids"])
+	if err != nil {
+		return err
+	}
+	arr, ok := kids.(Array)
+	if !ok || d["Type"] == Name("Page") {
+		page := Page{Dict: Dict{}}
+		if isRef {
+			page.Ref = ref
+		}
+		for key, value := range inherited {
+			page.Dict[key] = value
+		}
+		for key, value := range d {
+			page.Dict[key] = value
+		}
+		*pages = append(*pages, page)
+		return nil
+	}
+
+	attrs := inherited
+	copied := false
+	for _, key := range inheritable {
+		if value, ok := d[key]; ok {
+			if !copied {
+				attrs = Dict{}
+				for k, v := range inherited {
+					attrs[k] = v
+				}
+				copied = true
+			}
+			attrs[key] = value
+		}
+	}
+	for _, kid := range arr {
+		if err := r.walkPages(kid, attrs, tree, depth+1, pages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
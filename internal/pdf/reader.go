@@ -0,0 +1,741 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// searchSize is how far from the start of a file the header, and from the
+// end the end-of-file marker, are looked for.
+const searchSize = 1024
+
+// Reader reads the objects of a PDF file. It is not safe for concurrent
+// use.
+type Reader struct {
+	r        io.ReaderAt
+	size     int64
+	version  string
+	xref     map[int]xrefEntry
+	trailer  Dict
+	repaired bool
+
+	objects   map[int]Object
+	streams   map[int]*objectStream
+	resolving map[int]bool
+
+	pages    []Page
+	pageTree map[int]bool // numbers of the page tree's nodes and pages
+}
+
+type xrefEntry struct {
+	kind   byte  // 0 free, 1 at offset, 2 in an object stream
+	offset int64 // kind 1: byte offset; kind 2: number of the object stream
+	gen    int
+}
+
+// objectStream holds the decoded contents of an object stream.
+type objectStream struct {
+	data    []byte
+	offsets map[int]int64 // object number to offset in data
+}
+
+// Open reads the header, cross-reference data and trailer of the PDF file
+// of the given size. If the cross-reference data is damaged, the objects are
+// located by scanning the file, as PDF readers commonly do.
+func Open(r io.ReaderAt, size int64) (*Reader, error) {
+	head := make([]byte, min(size, searchSize))
+	if _, err := r.ReadAt(head, 0); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error reading PDF header: %w", err)
+	}
+	if !bytes.HasPrefix(head, []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+
+	tailStart := max(0, size-searchSize)
+	tail := make([]byte, size-tailStart)
+	if _, err := r.ReadAt(tail, tailStart); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error reading PDF trailer: %w", err)
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return nil, ErrTruncated
+	}
+
+	pr := &Reader{
+		r:       r,
+		size:    size,
+		version: headerVersion(head),
+	}
+	pr.reset()
+
+	err := pr.readXref(tail)
+	if err == nil {
+		_, err = pr.Catalog()
+	}
+	if err != nil {
+		pr.reset()
+		if rerr := pr.reconstruct(); rerr != nil {
+			return nil, err
+		}
+		pr.repaired = true
+	}
+	return pr, nil
+}
+
+func headerVersion(head []byte) string {
+	v := head[len("%PDF-"):]
+	end := 0
+	for end < len(v) && (v[end] == '.' || v[end] >= '0' && v[end] <= '9') {
+		end++
+	}
+	return string(v[:end])
+}
+
+func (r *Reader) reset() {
+	r.xref = make(map[int]xrefEntry)
+	r.trailer = nil
+	r.objects = make(map[int]Object)
+	r.streams = make(map[int]*objectStream)
+	r.resolving = make(map[int]bool)
+	r.pages = nil
+	r.pageTree = nil
+}
+
+// Version returns the PDF version in the file header, such as "1.7".
+func (r *Reader) Version() string {
+	return r.version
+}
+
+// Size returns the size of the file.
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+// Trailer returns the trailer dictionary. For files with incremental
+// updates it combines the trailers of every section, the latest taking
+// precedence.
+func (r *Reader) Trailer() Dict {
+	return r.trailer
+}
+
+// Repaired reports whether the cross-reference data was damaged and the
+// objects were located by scanning the file.
+func (r *Reader) Repaired() bool {
+	return r.repaired
+}
+
+// Encrypted reports whether the file is encrypted. Strings and streams of
+// encrypted files cannot be decoded.
+func (r *Reader) Encrypted() bool {
+	_, ok := r.trailer["Encrypt"]
+	return ok
+}
+
+// Catalog returns the document catalog.
+func (r *Reader) Catalog() (Dict, error) {
+	obj, err := r.Resolve(r.trailer["Root"])
+	if err != nil {
+		return nil, err
+	}
+	catalog, ok := obj.(Dict)
+	if !ok {
+		return nil, corruptf("missing document catalog")
+	}
+	return catalog, nil
+}
+
+// PageCount returns the number of pages, from the /Count of the page tree
+// or, if that is missing, by counting its leaves.
+func (r *Reader) PageCount() (int, error) {
+	catalog, err := r.Catalog()
+	if err != nil {
+		return 0, err
+	}
+	obj, err := r.Resolve(catalog["Pages"])
+	if err != nil {
+		return 0, err
+	}
+	root, ok := obj.(Dict)
+	if !ok {
+		return 0, corruptf("missing page tree")
+	}
+
+	count, err := r.Resolve(root["Count"])
+	if err != nil {
+		return 0, err
+	}
+	if n, ok := Int(count); ok && n >= 0 {
+		return int(n), nil
+	}
+	return r.countPages(root, map[int]bool{}, 0)
+}
+
+func (r *Reader) countPages(node Dict, visited map[int]bool, depth int) (int, error) {
+	if depth > maxDepth {
+		return 0, corruptf("page tree nested too deeply")
+	}
+
+	kids, err := r.Resolve(node["Kids"])
+	if err != nil {
+		return 0, err
+	}
+	arr, ok := kids.(Array)
+	if !ok {
+		return 1, nil
+	}
+
+	total := 0
+	for _, kid := range arr {
+		if ref, ok := kid.(Ref); ok {
+			if visited[ref.Num] {
+				return 0, corruptf("page tree contains a cycle")
+			}
+			visited[ref.Num] = true
+		}
+		obj, err := r.Resolve(kid)
+		if err != nil {
+			return 0, err
+		}
+		child, ok := obj.(Dict)
+		if !ok {
+			continue
+		}
+		n, err := r.countPages(child, visited, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Resolve returns the object obj refers to, or obj itself if it is not a
+// reference. References to missing objects resolve to null.
+func (r *Reader) Resolve(obj Object) (Object, error) {
+	ref, ok := obj.(Ref)
+	if !ok {
+		return obj, nil
+	}
+	return r.object(ref.Num)
+}
+
+// Decode returns the decoded data of a stream.
+func (r *Reader) Decode(s *Stream) ([]byte, error) {
+	if r.Encrypted() && s.Dict["Type"] != Name("XRef") {
+		return nil, ErrEncrypted
+	}
+	return decode(s, r.Resolve)
+}
+
+// Objects returns the numbers of the objects in the file in ascending
+// order.
+func (r *Reader) Objects() []int {
+	nums := make([]int, 0, len(r.xref))
+	for num, entry := range r.xref {
+		if entry.kind != 0 {
+			nums = append(nums, num)
+		}
+	}
+	slices.Sort(nums)
+	return nums
+}
+
+func (r *Reader) object(num int) (Object, error) {
+	if obj, ok := r.objects[num]; ok {
+		return obj, nil
+	}
+	if r.resolving[num] {
+		return nil, corruptf("object %d refers to itself", num)
+	}
+	r.resolving[num] = true
+	defer delete(r.resolving, num)
+
+	entry := r.xref[num]
+	var obj Object
+	var err error
+	switch entry.kind {
+	case 1:
+		var got int
+		got, obj, err = r.parseObjectAt(entry.offset)
+		if err == nil && got != num {
+			err = corruptf("object %d not found at offset %d", num, entry.offset)
+		}
+	case 2:
+		obj, err = r.compressedObject(int(entry.offset), num)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Stream data is read again when needed, so copying the pages of a
+	// large file does not hold all of it in memory.
+	if _, ok := obj.(*Stream); !ok {
+		r.objects[num] = obj
+	}
+	return obj, nil
+}
+
+// parseObjectAt parses the indirect object at offset, returning its number.
+func (r *Reader) parseObjectAt(offset int64) (int, Object, error) {
+	l := newLexer(r.r, r.size, offset)
+	num, ok := l.integer()
+	if !ok {
+		return 0, nil, corruptf("no object at offset %d", offset)
+	}
+	if _, ok := l.integer(); !ok || !l.keyword("obj") {
+		return 0, nil, corruptf("no object at offset %d", offset)
+	}
+
+	obj, err := l.object()
+	if err != nil {
+		return 0, nil, err
+	}
+	if kw, ok := obj.(keyword); ok {
+		if kw == "endobj" {
+			return int(num), nil, nil
+		}
+		return 0, nil, corruptf("unexpected %q in object %d", kw, num)
+	}
+
+	dict, ok := obj.(Dict)
+	if !ok || !l.keyword("stream") {
+		return int(num), obj, nil
+	}
+
+	// The data starts after the end of line following the keyword.
+	if c, _ := l.peek(); c == '\r' {
+		l.pos++
+	}
+	if c, _ := l.peek(); c == '\n' {
+		l.pos++
+	}
+	data, err := r.streamData(dict, l.pos)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int(num), &Stream{Dict: dict, Data: data}, nil
+}
+
+// streamData reads the data of a stream starting at start, using its
+// /Length if that is consistent with the file and otherwise looking for the
+// endstream keyword.
+func (r *Reader) streamData(dict Dict, start int64) ([]byte, error) {
+	length := int64(-1)
+	if obj, err := r.Resolve(dict["Length"]); err == nil {
+		if n, ok := Int(obj); ok {
+			length = n
+		}
+	}
+
+	end := int64(-1)
+	if length >= 0 && start+length <= r.size {
+		if newLexer(r.r, r.size, start+length).keyword("endstream") {
+			end = start + length
+		}
+	}
+	if end < 0 {
+		var err error
+		if end, err = r.findEndstream(start); err != nil {
+			return nil, err
+		}
+	}
+
+	data := make([]byte, end-start)
+	if _, err := r.r.ReadAt(data, start); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error reading stream: %w", err)
+	}
+	return data, nil
+}
+
+// findEndstream returns the end of the data of a stream starting at start,
+// before the end of line preceding the endstream keyword.
+func (r *Reader) findEndstream(start int64) (int64, error) {
+	const chunk = 64 << 10
+	marker := []byte("endstream")
+	buf := make([]byte, chunk+len(marker))
+
+	for pos := start; pos < r.size; pos += chunk {
+		n, _ := r.r.ReadAt(buf[:min(int64(len(buf)), r.size-pos)], pos)
+		i := bytes.Index(buf[:n], marker)
+		if i < 0 {
+			continue
+		}
+		end := pos + int64(i)
+		tail := buf[max(0, i-2):i]
+		switch {
+		case bytes.HasSuffix(tail, []byte("\r\n")):
+			end -= 2
+		case bytes.HasSuffix(tail, []byte("\n")), bytes.HasSuffix(tail, []byte("\r")):
+			end--
+		}
+		return max(end, start), nil
+	}
+	return 0, corruptf("unterminated stream at offset %d", start)
+}
+
+func (r *Reader) compressedObject(stream, num int) (Object, error) {
+	objs, err := r.objectStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	offset, ok := objs.offsets[num]
+	if !ok {
+		return nil, corruptf("object %d not found in object stream %d", num, stream)
+	}
+
+	obj, err := newLexer(bytes.NewReader(objs.data), int64(len(objs.data)), offset).object()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := obj.(keyword); ok {
+		return nil, corruptf("unexpected %q in object %d", obj, num)
+	}
+	return obj, nil
+}
+
+func (r *Reader) objectStream(num int) (*objectStream, error) {
+	if objs, ok := r.streams[num]; ok {
+		return objs, nil
+	}
+
+	obj, err := r.object(num)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(*Stream)
+	if !ok {
+		return nil, corruptf("object stream %d not found", num)
+	}
+	data, err := r.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	n, _ := r.Resolve(s.Dict["N"])
+	first, _ := r.Resolve(s.Dict["First"])
+	count, ok1 := Int(n)
+	start, ok2 := Int(first)
+	if !ok1 || !ok2 || count < 0 || start < 0 {
+		return nil, corruptf("invalid object stream %d", num)
+	}
+
+	objs := &objectStream{data: data, offsets: make(map[int]int64)}
+	l := newLexer(bytes.NewReader(data), int64(len(data)), 0)
+	for i := int64(0); i < count; i++ {
+		objNum, ok1 := l.integer()
+		offset, ok2 := l.integer()
+		if !ok1 || !ok2 {
+			return nil, corruptf("invalid object stream %d", num)
+		}
+		objs.offsets[int(objNum)] = start + offset
+	}
+
+	r.streams[num] = objs
+	return objs, nil
+}
+
+// readXref reads the cross-reference sections, starting with the one
+// startxref in tail points to and following /Prev to earlier ones.
+func (r *Reader) readXref(tail []byte) error {
+	i := bytes.LastIndex(tail, []byte("startxref"))
+	if i < 0 {
+		return corruptf("missing startxref")
+	}
+	offset, ok := newLexer(bytes.NewReader(tail), int64(len(tail)), int64(i+len("startxref"))).integer()
+	if !ok {
+		return corruptf("invalid startxref")
+	}
+
+	visited := make(map[int64]bool)
+	r.trailer = Dict{}
+	for !visited[offset] {
+		visited[offset] = true
+
+		trailer, err := r.readXrefSection(offset)
+		if err != nil {
+			return err
+		}
+		// In a hybrid file, the entries of the stream at /XRefStm come
+		// after those of the table but before earlier sections.
+		if stm, ok := Int(trailer["XRefStm"]); ok && !visited[stm] {
+			visited[stm] = true
+			if _, err := r.readXrefStream(stm); err != nil {
+				return err
+			}
+		}
+		for key, value := range trailer {
+			if _, ok := r.trailer[key]; !ok {
+				r.trailer[key] = value
+			}
+		}
+
+		prev, ok := Int(trailer["Prev"])
+		if !ok {
+			break
+		}
+		offset = prev
+	}
+
+	delete(r.trailer, "Prev")
+	delete(r.trailer, "XRefStm")
+	if _, ok := r.trailer["Root"]; !ok {
+		return corruptf("trailer has no /Root")
+	}
+	return nil
+}
+
+func (r *Reader) readXrefSection(offset int64) (Dict, error) {
+	if offset < 0 || offset >= r.size {
+		return nil, corruptf("cross-reference offset %d is outside the file", offset)
+	}
+	l := newLexer(r.r, r.size, offset)
+	if l.keyword("xref") {
+		return r.readXrefTable(l)
+	}
+	return r.readXrefStream(offset)
+}
+
+func (r *Reader) readXrefTable(l *lexer) (Dict, error) {
+	for {
+		if l.keyword("trailer") {
+			obj, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			trailer, ok := obj.(Dict)
+			if !ok {
+				return nil, corruptf("invalid trailer")
+			}
+			return trailer, nil
+		}
+
+		first, ok1 := l.integer()
+		count, ok2 := l.integer()
+		if !ok1 || !ok2 {
+			return nil, corruptf("invalid cross-reference table at offset %d", l.pos)
+		}
+		for i := int64(0); i < count; i++ {
+			offset, ok1 := l.integer()
+			gen, ok2 := l.integer()
+			l.skipSpace()
+			kind := string(l.regular())
+			if !ok1 || !ok2 || (kind != "n" && kind != "f") {
+				return nil, corruptf("invalid cross-reference entry at offset %d", l.pos)
+			}
+
+			num := int(first + i)
+			if _, ok := r.xref[num]; ok {
+				continue
+			}
+			if kind == "n" {
+				r.xref[num] = xrefEntry{kind: 1, offset: offset, gen: int(gen)}
+			} else {
+				r.xref[num] = xrefEntry{}
+			}
+		}
+	}
+}
+
+func (r *Reader) readXrefStream(offset int64) (Dict, error) {
+	_, obj, err := r.parseObjectAt(offset)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(*Stream)
+	if !ok || s.Dict["Type"] != Name("XRef") {
+		return nil, corruptf("no cross-reference stream at offset %d", offset)
+	}
+
+	// The entries of a cross-reference stream are always direct.
+	direct := func(obj Object) (Object, error) { return obj, nil }
+	data, err := decode(s, direct)
+	if err != nil {
+		return nil, err
+	}
+
+	widths, _ := s.Dict["W"].(Array)
+	if len(widths) != 3 {
+		return nil, corruptf("invalid cross-reference stream at offset %d", offset)
+	}
+	var w [3]int
+	for i, obj := range widths {
+		n, ok := Int(obj)
+		if !ok || n < 0 || n > 8 {
+			return nil, corruptf("invalid cross-reference stream at offset %d", offset)
+		}
+		w[i] = int(n)
+	}
+	entrySize := w[0] + w[1] + w[2]
+
+	index, _ := s.Dict["Index"].(Array)
+	if index == nil {
+		size, _ := Int(s.Dict["Size"])
+		index = Array{int64(0), size}
+	}
+
+	field := func(b []byte) int64 {
+		var v int64
+		for _, c := range b {
+			v = v<<8 | int64(c)
+		}
+		return v
+	}
+
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		first, ok1 := Int(index[i])
+		count, ok2 := Int(index[i+1])
+		if !ok1 || !ok2 {
+			return nil, corruptf("invalid cross-reference stream at offset %d", offset)
+		}
+		for j := int64(0); j < count; j++ {
+			if pos+entrySize > len(data) {
+				return nil, corruptf("truncated cross-reference stream at offset %d", offset)
+			}
+			entry := data[pos : pos+entrySize]
+			pos += entrySize
+
+			kind := int64(1)
+			if w[0] > 0 {
+				kind = field(entry[:w[0]])
+			}
+			f2 := field(entry[w[0] : w[0]+w[1]])
+			f3 := field(entry[w[0]+w[1]:])
+
+			num := int(first + j)
+			if _, ok := r.xref[num]; ok {
+				continue
+			}
+			switch kind {
+			case 0:
+				r.xref[num] = xrefEntry{}
+			case 1:
+				r.xref[num] = xrefEntry{kind: 1, offset: f2, gen: int(f3)}
+			case 2:
+				r.xref[num] = xrefEntry{kind: 2, offset: f2}
+			}
+		}
+	}
+	return s.Dict, nil
+}
+
+var objectPattern = regexp.MustCompile(`(\d+)[ \t\r\n\f\x00]+(\d+)[ \t\r\n\f\x00]+obj\b`)
+
+// reconstructWindow is the number of bytes of the file reconstruct scans at
+// a time, and reconstructOverlap how far each window extends into the next,
+// which must cover the longest object header or keyword it looks for.
+const (
+	reconstructWindow  = 1 << 20
+	reconstructOverlap = 256
+)
+
+// reconstruct locates the objects and trailer by scanning the whole file,
+// a window at a time.
+func (r *Reader) reconstruct() error {
+	buf := make([]byte, min(r.size, reconstructWindow+reconstructOverlap+1))
+	var trailers []int64
+	for start := int64(0); start < r.size; start += reconstructWindow {
+		// Each window starts a byte early so that the byte before a match
+		// can be checked, and matches are kept only if they start within
+		// the window, so that none is found twice.
+		from := max(start-1, 0)
+		data := buf[:min(int64(len(buf)), r.size-from)]
+		if _, err := r.r.ReadAt(data, from); err != nil && err != io.EOF {
+			return fmt.Errorf("error reading PDF: %w", err)
+		}
+		inWindow := func(i int) bool {
+			pos := from + int64(i)
+			return pos >= start && pos < start+reconstructWindow
+		}
+
+		// Later definitions of an object, from incremental updates,
+		// replace earlier ones.
+		for _, m := range objectPattern.FindAllSubmatchIndex(data, -1) {
+			if !inWindow(m[2]) {
+				continue
+			}
+			if m[2] > 0 && !isSpace(data[m[2]-1]) && !isDelimiter(data[m[2]-1]) {
+				continue
+			}
+			num, err1 := strconv.Atoi(string(data[m[2]:m[3]]))
+			gen, err2 := strconv.Atoi(string(data[m[4]:m[5]]))
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			r.xref[num] = xrefEntry{kind: 1, offset: from + int64(m[2]), gen: gen}
+		}
+
+		for pos := 0; ; {
+			i := bytes.Index(data[pos:], []byte("trailer"))
+			if i < 0 {
+				break
+			}
+			if inWindow(pos + i) {
+				trailers = append(trailers, from+int64(pos+i))
+			}
+			pos += i + len("trailer")
+		}
+	}
+
+	r.trailer = Dict{}
+	for _, pos := range trailers {
+		obj, err := newLexer(r.r, r.size, pos+int64(len("trailer"))).object()
+		if trailer, ok := obj.(Dict); err == nil && ok {
+			for key, value := range trailer {
+				r.trailer[key] = value
+			}
+		}
+	}
+
+	// Add the contents of object streams, and use cross-reference streams
+	// if no trailer was found.
+	for _, num := range r.Objects() {
+		obj, err := r.object(num)
+		if err != nil {
+			continue
+		}
+		s, ok := obj.(*Stream)
+		if !ok {
+			continue
+		}
+		switch s.Dict["Type"] {
+		case Name("ObjStm"):
+			if objs, err := r.objectStream(num); err == nil {
+				for objNum := range objs.offsets {
+					if _, ok := r.xref[objNum]; !ok {
+						r.xref[objNum] = xrefEntry{kind: 2, offset: int64(num)}
+					}
+				}
+			}
+		case Name("XRef"):
+			for key, value := range s.Dict {
+				if _, ok := r.trailer[key]; !ok {
+					r.trailer[key] = value
+				}
+			}
+		}
+	}
+
+	// Failing that, look for the catalog itself.
+	var catalog *Ref
+	if _, err := r.Catalog(); err != nil {
+		for _, num := range r.Objects() {
+			obj, err := r.object(num)
+			if d, ok := obj.(Dict); err == nil && ok && d["Type"] == Name("Catalog") {
+				catalog = &Ref{Num: num, Gen: r.xref[num].gen}
+				break
+			}
+		}
+	}
+
+	delete(r.trailer, "Prev")
+	delete(r.trailer, "XRefStm")
+	if _, err := r.Catalog(); err != nil && catalog != nil {
+		r.trailer["Root"] = *catalog
+	}
+	_, err := r.Catalog()
+	return err
+}
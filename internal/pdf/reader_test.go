@@ -0,0 +1,281 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mhpenta/pypdftotext-client/internal/pdf"
+)
+
+// writePDF returns a PDF with the given objects, numbered from 1, and a
+// cross-reference table. Object 1 is the catalog.
+func writePDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+func compress(data []byte) []byte {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(data)
+	w.Close()
+	return b.Bytes()
+}
+
+// writeCompressedPDF returns a PDF storing the given objects, numbered from
+// 1, in an object stream located by a cross-reference stream with the PNG
+// Up predictor.
+func writeCompressedPDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+
+	var header, body bytes.Buffer
+	for i, obj := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	stream := compress(append(header.Bytes(), body.Bytes()...))
+	objStm := len(objects) + 1
+	objStmOffset := b.Len()
+	fmt.Fprintf(&b, "%d 0 obj\n<< /Type /ObjStm /N %d /First %d /Filter /FlateDecode /Length %d >>\nstream\n",
+		objStm, len(objects), header.Len(), len(stream))
+	b.Write(stream)
+	b.WriteString("\nendstream\nendobj\n")
+
+	xrefNum := objStm + 1
+	xrefOffset := b.Len()
+	row := func(kind byte, f2, f3 int) []byte {
+		return []byte{kind, byte(f2 >> 24), byte(f2 >> 16), byte(f2 >> 8), byte(f2), byte(f3 >> 8), byte(f3)}
+	}
+	rows := [][]byte{row(0, 0, 65535)}
+	for i := range objects {
+		rows = append(rows, row(2, objStm, i))
+	}
+	rows = append(rows, row(1, objStmOffset, 0), row(1, xrefOffset, 0))
+
+	var data []byte
+	prev := make([]byte, 7)
+	for _, r := range rows {
+		data = append(data, 2)
+		for i := range r {
+			data = append(data, r[i]-prev[i])
+		}
+		prev = r
+	}
+	data = compress(data)
+
+	fmt.Fprintf(&b, "%d 0 obj\n<< /Type /XRef /Size %d /W [1 4 2] /Root 1 0 R /Filter /FlateDecode "+
+		"/DecodeParms << /Predictor 12 /Columns 7 >> /Length %d >>\nstream\n", xrefNum, xrefNum+1, len(data))
+	b.Write(data)
+	fmt.Fprintf(&b, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xrefOffset)
+	return b.Bytes()
+}
+
+func open(t *testing.T, data []byte) *pdf.Reader {
+	t.Helper()
+	r, err := pdf.Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return r
+}
+
+var threePages = []string{
+	"<< /Type /Catalog /Pages 2 0 R >>",
+	"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R] /Count 3 >>",
+	"<< /Type /Page /Parent 2 0 R >>",
+	"<< /Type /Page /Parent 2 0 R >>",
+	"<< /Type /Page /Parent 2 0 R >>",
+}
+
+func TestOpen_Fixture(t *testing.T) {
+	data, err := os.ReadFile("../../fixtures/example.pdf")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	r := open(t, data)
+
+	if r.Version() != "1.7" {
+		t.Errorf("Version() = %v, want %v", r.Version(), "1.7")
+	}
+	if n, err := r.PageCount(); err != nil || n != 15 {
+		t.Errorf("PageCount() = %v, %v, want %v", n, err, 15)
+	}
+	if r.Repaired() || r.Encrypted() {
+		t.Errorf("Repaired() = %v, Encrypted() = %v, want false", r.Repaired(), r.Encrypted())
+	}
+
+	catalog, err := r.Catalog()
+	if err != nil {
+		t.Fatalf("Catalog() error = %v", err)
+	}
+	markInfo, _ := r.Resolve(catalog["MarkInfo"])
+	if d, _ := markInfo.(pdf.Dict); d["Marked"] != true {
+		t.Errorf("catalog /MarkInfo = %v, want /Marked true", markInfo)
+	}
+}
+
+func TestOpen_CompressedObjects(t *testing.T) {
+	r := open(t, writeCompressedPDF(threePages...))
+
+	if n, err := r.PageCount(); err != nil || n != 3 {
+		t.Errorf("PageCount() = %v, %v, want %v", n, err, 3)
+	}
+	if r.Repaired() {
+		t.Errorf("Repaired() = true, want false")
+	}
+	page, err := r.Resolve(pdf.Ref{Num: 4})
+	if d, ok := page.(pdf.Dict); err != nil || !ok || d["Type"] != pdf.Name("Page") {
+		t.Errorf("Resolve(4 0 R) = %v, %v, want a page", page, err)
+	}
+}
+
+func TestOpen_CountsPagesWithoutCount(t *testing.T) {
+	objects := append([]string{}, threePages...)
+	objects[1] = "<< /Type /Pages /Kids [6 0 R 5 0 R] >>"
+	objects = append(objects, "<< /Type /Pages /Parent 2 0 R /Kids [3 0 R 4 0 R] >>")
+	r := open(t, writePDF(objects...))
+
+	if n, err := r.PageCount(); err != nil || n != 3 {
+		t.Errorf("PageCount() = %v, %v, want %v", n, err, 3)
+	}
+}
+
+func TestOpen_Repair(t *testing.T) {
+	tests := map[string][]byte{
+		"wrong startxref": bytes.Replace(writePDF(threePages...), []byte("startxref\n"), []byte("startxref\n1"), 1),
+		"no xref":         []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n2 0 obj\n<< /Type /Pages /Kids [] /Count 0 >>\nendobj\n%%EOF\n"),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := open(t, data)
+			if !r.Repaired() {
+				t.Errorf("Repaired() = false, want true")
+			}
+			if _, err := r.PageCount(); err != nil {
+				t.Errorf("PageCount() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOpen_RepairLargeFile(t *testing.T) {
+	// Object headers and the trailer straddle the boundaries of the windows
+	// the file is scanned in.
+	const window = 1 << 20
+	offsets := []int{window - 3, window + 100, 2*window - 1, 2*window + 50, 2*window + 200}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	padTo := func(offset int) {
+		b.WriteString("%" + strings.Repeat("x", offset-b.Len()-2) + "\n")
+	}
+	for i, obj := range threePages {
+		padTo(offsets[i])
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	padTo(3*window - 4)
+	b.WriteString("trailer\n<< /Size 6 /Root 1 0 R >>\nstartxref\n1\n%%EOF\n")
+
+	r := open(t, b.Bytes())
+	if !r.Repaired() {
+		t.Errorf("Repaired() = false, want true")
+	}
+	if n, err := r.PageCount(); err != nil || n != 3 {
+		t.Errorf("PageCount() = %v, %v, want %v", n, err, 3)
+	}
+	if got := len(r.Objects()); got != len(threePages) {
+		t.Errorf("Objects() found %d objects, want %d", got, len(threePages))
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	valid := writePDF(threePages...)
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, pdf.ErrNotPDF},
+		{"HTML", []byte("<!DOCTYPE html><html><body>Not Found</body></html>"), pdf.ErrNotPDF},
+		{"truncated", valid[:len(valid)/2], pdf.ErrTruncated},
+		{"no catalog", []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\ntrailer\n<<>>\n%%EOF\n"), pdf.ErrCorrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pdf.Open(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Open() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen_Encrypted(t *testing.T) {
+	data := bytes.Replace(writePDF(threePages...), []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt << /Filter /Standard /V 2 >>"), 1)
+	r := open(t, data)
+
+	if !r.Encrypted() {
+		t.Errorf("Encrypted() = false, want true")
+	}
+	if n, err := r.PageCount(); err != nil || n != 3 {
+		t.Errorf("PageCount() = %v, %v, want %v", n, err, 3)
+	}
+}
+
+func TestResolve_Syntax(t *testing.T) {
+	r := open(t, writePDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] /Count 0 >>",
+		`<< /Literal (a \(nested (pair)\) \101\n\
+end) /Hex <48 65 6C6C 6F> /Name /A#20B /Real -1.5 /Array [1 0 R 2 (x) null true] % comment
+/Null null >>`,
+	))
+
+	obj, err := r.Resolve(pdf.Ref{Num: 3})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	d := obj.(pdf.Dict)
+
+	checks := []struct {
+		key  pdf.Name
+		want any
+	}{
+		{"Literal", pdf.String("a (nested (pair)) A\nend")},
+		{"Hex", pdf.String("Hello")},
+		{"Name", pdf.Name("A B")},
+		{"Real", -1.5},
+	}
+	for _, c := range checks {
+		if d[c.key] != c.want {
+			t.Errorf("/%s = %#v, want %#v", c.key, d[c.key], c.want)
+		}
+	}
+	if arr, _ := d["Array"].(pdf.Array); len(arr) != 5 || arr[0] != (pdf.Ref{Num: 1}) || arr[1] != int64(2) || arr[3] != nil {
+		t.Errorf("/Array = %#v, want [1 0 R 2 (x) null true]", d["Array"])
+	}
+	if _, ok := d["Null"]; ok {
+		t.Errorf("/Null present, want null entries dropped")
+	}
+}
@@ -0,0 +1,260 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// WritePages writes a PDF document holding the given pages of r, indexes
+// into Pages, to w in that order. The page tree is rebuilt with the
+// attributes the pages inherit copied onto them, and the objects the pages
+// use, such as fonts, images and annotations, are copied and renumbered.
+// Document-level structures that span pages, such as the outline and the
+// structure tree, are not copied, and references to pages that are not
+// written become null.
+//
+// The output is deterministic: the same pages of the same file always
+// produce the same bytes.
+func (r *Reader) WritePages(w io.Writer, pages []int) error {
+	if r.Encrypted() {
+		return ErrEncrypted
+	}
+	all, err := r.Pages()
+	if err != nil {
+		return err
+	}
+
+	pw := &writer{
+		r:       r,
+		w:       w,
+		numbers: map[int]int{},
+		next:    3 + len(pages),
+	}
+	// Objects 1 and 2 are the catalog and the page tree root, followed by
+	// the pages.
+	kids := make(Array, len(pages))
+	for i, index := range pages {
+		if index < 0 || index >= len(all) {
+			return fmt.Errorf("page index %d out of range", index)
+		}
+		kids[i] = Ref{Num: 3 + i}
+		if ref := all[index].Ref; ref.Num != 0 {
+			pw.numbers[ref.Num] = 3 + i
+		}
+	}
+	if root, ok := r.trailer["Root"].(Ref); ok {
+		pw.exclude(root.Num)
+	}
+
+	version := r.version
+	if version == "" {
+		version = "1.4"
+	}
+	pw.write([]byte("%PDF-" + version + "\n%\xe2\xe3\xcf\xd3\n"))
+	pw.writeObject(1, Dict{"Type": Name("Catalog"), "Pages": Ref{Num: 2}})
+	pw.writeObject(2, Dict{"Type": Name("Pages"), "Kids": kids, "Count": int64(len(pages))})
+
+	for i, index := range pages {
+		page := Dict{}
+		for key, value := range all[index].Dict {
+			switch key {
+			case "Parent", "B":
+				// The parent is replaced, and article beads link to the
+				// rest of the document.
+			default:
+				page[key] = value
+			}
+		}
+		page = pw.copy(page).(Dict)
+		page["Parent"] = Ref{Num: 2}
+		pw.writeObject(3+i, page)
+	}
+
+	for len(pw.queue) > 0 && pw.err == nil {
+		num := pw.queue[0]
+		pw.queue = pw.queue[1:]
+		obj, err := r.object(num)
+		if err != nil {
+			// Unreadable objects are dropped, as they would be by a PDF
+			// reader.
+			obj = nil
+		}
+		pw.writeObject(pw.numbers[num], pw.copy(obj))
+	}
+
+	xref := pw.offset
+	size := len(pw.offsets) + 1
+	buf := fmt.Appendf(nil, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for num := 1; num < size; num++ {
+		buf = fmt.Appendf(buf, "%010d 00000 n \n", pw.offsets[num-1])
+	}
+	buf = fmt.Appendf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, xref)
+	pw.write(buf)
+	return pw.err
+}
+
+// writer copies objects from a Reader to a new file.
+type writer struct {
+	r       *Reader
+	w       io.Writer
+	offset  int64
+	offsets []int64 // offset of each object, from object 1
+	numbers map[int]int
+	next    int   // next unassigned object number
+	queue   []int // numbers in r of objects assigned but not yet written
+	err     error
+}
+
+// exclude makes references to the object num in r become null.
+func (w *writer) exclude(num int) {
+	w.numbers[num] = 0
+}
+
+// copy returns obj with the references in it renumbered, assigning numbers
+// to the objects it refers to and queueing them to be written.
+func (w *writer) copy(obj Object) Object {
+	switch v := obj.(type) {
+	case Ref:
+		num, ok := w.numbers[v.Num]
+		if !ok && w.r.pageTree[v.Num] {
+			// A page that is not written, or a node of the old page tree.
+			w.exclude(v.Num)
+			return nil
+		}
+		if !ok {
+			num = w.next
+			w.next++
+			w.numbers[v.Num] = num
+			w.queue = append(w.queue, v.Num)
+		}
+		if num == 0 {
+			return nil
+		}
+		return Ref{Num: num}
+	case Array:
+		arr := make(Array, len(v))
+		for i, item := range v {
+			arr[i] = w.copy(item)
+		}
+		return arr
+	case Dict:
+		// Keys are visited in order so objects are numbered the same way
+		// every time.
+		d := make(Dict, len(v))
+		for _, key := range sortedKeys(v) {
+			if value := w.copy(v[key]); value != nil {
+				d[key] = value
+			}
+		}
+		return d
+	case *Stream:
+		d := w.copy(v.Dict).(Dict)
+		d["Length"] = int64(len(v.Data))
+		return &Stream{Dict: d, Data: v.Data}
+	}
+	return obj
+}
+
+func (w *writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+	n, err := w.w.Write(p)
+	w.offset += int64(n)
+	w.err = err
+}
+
+func (w *writer) writeObject(num int, obj Object) {
+	for len(w.offsets) < num {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[num-1] = w.offset
+
+	buf := strconv.AppendInt(nil, int64(num), 10)
+	buf = append(buf, " 0 obj\n"...)
+	if s, ok := obj.(*Stream); ok {
+		buf = appendObject(buf, s.Dict)
+		buf = append(buf, "\nstream\n"...)
+		w.write(buf)
+		w.write(s.Data)
+		buf = []byte("\nendstream")
+	} else {
+		buf = appendObject(buf, obj)
+	}
+	w.write(append(buf, "\nendobj\n"...))
+}
+
+func sortedKeys(d Dict) []Name {
+	keys := make([]Name, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// appendObject appends the PDF syntax for obj to buf. Streams must be
+// written by the caller.
+func appendObject(buf []byte, obj Object) []byte {
+	switch v := obj.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case float64:
+		return strconv.AppendFloat(buf, v, 'f', -1, 64)
+	case Name:
+		return appendName(buf, v)
+	case String:
+		buf = append(buf, '(')
+		for i := 0; i < len(v); i++ {
+			switch c := v[i]; c {
+			case '(', ')', '\\':
+				buf = append(buf, '\\', c)
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			default:
+				buf = append(buf, c)
+			}
+		}
+		return append(buf, ')')
+	case Ref:
+		return fmt.Appendf(buf, "%d %d R", v.Num, v.Gen)
+	case Array:
+		buf = append(buf, '[')
+		for i, item := range v {
+			if i > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = appendObject(buf, item)
+		}
+		return append(buf, ']')
+	case Dict:
+		buf = append(buf, "<<"...)
+		for _, key := range sortedKeys(v) {
+			buf = append(buf, ' ')
+			buf = appendName(buf, key)
+			buf = append(buf, ' ')
+			buf = appendObject(buf, v[key])
+		}
+		return append(buf, " >>"...)
+	}
+	return append(buf, "null"...)
+}
+
+func appendName(buf []byte, name Name) []byte {
+	buf = append(buf, '/')
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < '!' || c > '~' || c == '#' || isDelimiter(c) {
+			buf = fmt.Appendf(buf, "#%02X", c)
+		} else {
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
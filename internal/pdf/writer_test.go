@@ -0,0 +1,119 @@
+package pdf_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/mhpenta/pypdftotext-client/internal/pdf"
+)
+
+func writePages(t *testing.T, r *pdf.Reader, pages ...int) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := r.WritePages(&b, pages); err != nil {
+		t.Fatalf("WritePages() error = %v", err)
+	}
+	return b.Bytes()
+}
+
+func TestWritePages_Fixture(t *testing.T) {
+	data, err := os.ReadFile("../../fixtures/example.pdf")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	r := open(t, data)
+
+	pages, err := r.Pages()
+	if err != nil || len(pages) != 15 {
+		t.Fatalf("Pages() = %d pages, %v, want %d", len(pages), err, 15)
+	}
+
+	out := writePages(t, r, 0, 14, 7)
+	if again := writePages(t, r, 0, 14, 7); !bytes.Equal(out, again) {
+		t.Errorf("WritePages() output differs between calls")
+	}
+	if len(out) >= len(data) {
+		t.Errorf("WritePages() wrote %d bytes, want fewer than the %d of the whole file", len(out), len(data))
+	}
+
+	part := open(t, out)
+	if part.Repaired() {
+		t.Errorf("written file Repaired() = true, want false")
+	}
+	written, err := part.Pages()
+	if err != nil || len(written) != 3 {
+		t.Fatalf("written file Pages() = %d pages, %v, want %d", len(written), err, 3)
+	}
+	for i, page := range written {
+		if page.Dict["MediaBox"] == nil || page.Dict["Resources"] == nil {
+			t.Errorf("page %d = %v, want MediaBox and Resources", i, page.Dict)
+		}
+		contents, err := part.Resolve(page.Dict["Contents"])
+		if err != nil {
+			t.Fatalf("Resolve(Contents) error = %v", err)
+		}
+		if s, ok := contents.(*pdf.Stream); ok {
+			if _, err := part.Decode(s); err != nil {
+				t.Errorf("page %d Decode(Contents) error = %v", i, err)
+			}
+		}
+	}
+}
+
+func TestWritePages_InheritedAttributes(t *testing.T) {
+	r := open(t, writePDF(
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 8 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 3 /MediaBox [0 0 612 792] /Resources << /Font << /F1 7 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [5 0 R] /Count 1 /Rotate 90 /MediaBox [0 0 100 100] >>",
+		"<< /Type /Page /Parent 4 0 R /Annots [<< /Subtype /Link /Dest [3 0 R /Fit] >>] /Label (a \\(b\\) c) /Name /A#20B >>",
+		"<< /Length 0 >>\nstream\n\nendstream",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Outlines /First 3 0 R >>",
+	))
+
+	part := open(t, writePages(t, r, 1))
+	pages, err := part.Pages()
+	if err != nil || len(pages) != 1 {
+		t.Fatalf("written file Pages() = %d pages, %v, want %d", len(pages), err, 1)
+	}
+	page := pages[0].Dict
+
+	if page["Rotate"] != int64(90) {
+		t.Errorf("page /Rotate = %v, want %v", page["Rotate"], 90)
+	}
+	if box, _ := page["MediaBox"].(pdf.Array); len(box) != 4 || box[2] != int64(100) {
+		t.Errorf("page /MediaBox = %v, want [0 0 100 100]", page["MediaBox"])
+	}
+	resources, _ := part.Resolve(page["Resources"])
+	fonts, _ := part.Resolve(resources.(pdf.Dict)["Font"])
+	font, _ := part.Resolve(fonts.(pdf.Dict)["F1"])
+	if d, _ := font.(pdf.Dict); d["BaseFont"] != pdf.Name("Helvetica") {
+		t.Errorf("page font = %v, want Helvetica", font)
+	}
+	if page["Label"] != pdf.String("a (b) c") || page["Name"] != pdf.Name("A B") {
+		t.Errorf("page /Label = %q, /Name = %q, want them unchanged", page["Label"], page["Name"])
+	}
+
+	// The link to the page that was not written, the outline and the first
+	// page's contents are dropped.
+	annots, _ := part.Resolve(page["Annots"])
+	dest := annots.(pdf.Array)[0].(pdf.Dict)["Dest"].(pdf.Array)
+	if dest[0] != nil {
+		t.Errorf("link /Dest = %v, want a null page", dest)
+	}
+	if n := len(part.Objects()); n != 4 {
+		t.Errorf("written file has %d objects, want %d", n, 4)
+	}
+}
+
+func TestWritePages_Encrypted(t *testing.T) {
+	data := bytes.Replace(writePDF(threePages...), []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt << /Filter /Standard >>"), 1)
+	r := open(t, data)
+
+	if err := r.WritePages(&bytes.Buffer{}, []int{0}); !errors.Is(err, pdf.ErrEncrypted) {
+		t.Errorf("WritePages() error = %v, want ErrEncrypted", err)
+	}
+}
@@ -0,0 +1,90 @@
+package pdfclient
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// WithLogger sets the logger used for request events. Requests, responses,
+// retries and failures are logged at debug level; problems that do not fail
+// the call, such as errors closing a response body, at error level.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.Logger = logger
+	}
+}
+
+// WithBodyLogging includes up to maxBytes of each request and response body
+// in the debug log events. Zero disables body logging.
+func WithBodyLogging(maxBytes int) ClientOption {
+	return func(c *Client) {
+		c.LogBodyLimit = maxBytes
+	}
+}
+
+var (
+	debugLoggerOnce sync.Once
+	debugLogger     *slog.Logger
+)
+
+// logger returns the logger for request events. Without a configured Logger
+// debug mode logs to stderr, and other events go to the slog default logger.
+func (c *Client) logger() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	if c.Debug {
+		debugLoggerOnce.Do(func() {
+			debugLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		})
+		return debugLogger
+	}
+	return slog.Default()
+}
+
+const redacted = "REDACTED"
+
+// redactHeaders returns a copy of header with credentials replaced.
+func redactHeaders(header http.Header) http.Header {
+	clone := header.Clone()
+	for _, key := range []string{"X-API-Key", "Authorization"} {
+		if clone.Get(key) != "" {
+			clone.Set(key, redacted)
+		}
+	}
+	return clone
+}
+
+// countingReader counts the bytes read through it and keeps the first limit
+// of them for logging.
+type countingReader struct {
+	r       io.Reader
+	n       int64
+	limit   int
+	capture []byte
+	err     error // first read error other than io.EOF
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if err != nil && err != io.EOF && c.err == nil {
+		c.err = err
+	}
+	if room := c.limit - len(c.capture); room > 0 && n > 0 {
+		c.capture = append(c.capture, p[:min(n, room)]...)
+	}
+	return n, err
+}
+
+// bodyAttr returns the captured body for logging, marking it if truncated.
+func (c *countingReader) bodyAttr(key string) slog.Attr {
+	body := string(c.capture)
+	if c.n > int64(len(c.capture)) {
+		body += "...(truncated)"
+	}
+	return slog.String(key, body)
+}
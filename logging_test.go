@@ -0,0 +1,138 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+// logRecorder is a JSON slog handler output that can be decoded into events.
+type logRecorder struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *logRecorder) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *logRecorder) events(t *testing.T) []map[string]any {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(l.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func newRecordingLogger() (*slog.Logger, *logRecorder) {
+	recorder := &logRecorder{}
+	return slog.New(slog.NewJSONHandler(recorder, &slog.HandlerOptions{Level: slog.LevelDebug})), recorder
+}
+
+func TestLogger_RequestEvents(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithAPIKey("secret"))
+	defer server.Close()
+
+	logger, recorder := newRecordingLogger()
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithAPIKey("secret"),
+		pdfclient.WithLogger(logger),
+		pdfclient.WithBodyLogging(16),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	events := recorder.events(t)
+	if len(events) != 2 {
+		t.Fatalf("logged %d events, want 2: %v", len(events), events)
+	}
+
+	request, response := events[0], events[1]
+	if request["msg"] != "pdfclient request" || response["msg"] != "pdfclient response" {
+		t.Fatalf("logged events %v, %v", request["msg"], response["msg"])
+	}
+
+	if request["file"] != "test.pdf" {
+		t.Errorf("request event file = %v, want %v", request["file"], "test.pdf")
+	}
+
+	headers, _ := json.Marshal(request["headers"])
+	if strings.Contains(string(headers), "secret") || !strings.Contains(string(headers), "REDACTED") {
+		t.Errorf("request event headers = %s, want API key redacted", headers)
+	}
+
+	if response["status"] != float64(200) {
+		t.Errorf("response event status = %v, want 200", response["status"])
+	}
+
+	if sent, _ := response["bytes_sent"].(float64); sent <= float64(len(testPDF)) {
+		t.Errorf("response event bytes_sent = %v, want more than %d", response["bytes_sent"], len(testPDF))
+	}
+
+	if received, _ := response["bytes_received"].(float64); received == 0 {
+		t.Errorf("response event bytes_received = %v, want > 0", response["bytes_received"])
+	}
+
+	if _, ok := response["duration"]; !ok {
+		t.Error("response event has no duration")
+	}
+
+	body, _ := response["response_body"].(string)
+	if !strings.HasSuffix(body, "...(truncated)") || len(body) != 16+len("...(truncated)") {
+		t.Errorf("response event response_body = %q, want 16 bytes and a truncation marker", body)
+	}
+}
+
+func TestLogger_RetryAndFailureEvents(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.ServerError(503), pdfclienttest.OnFirst(1)))
+	defer server.Close()
+
+	logger, recorder := newRecordingLogger()
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithLogger(logger),
+		pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+
+	var messages []string
+	for _, event := range recorder.events(t) {
+		messages = append(messages, event["msg"].(string))
+	}
+
+	want := "pdfclient request,pdfclient request failed,pdfclient retrying request,pdfclient request,pdfclient response"
+	if got := strings.Join(messages, ","); got != want {
+		t.Errorf("logged events = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package pdfclient
+
+import (
+	"context"
+	"time"
+)
+
+// Operation names reported to observers.
+const (
+	OpHealthCheck = "health_check"
+	OpExtract     = "extract"
+	OpExtractGCS  = "extract_gcs"
+	// OpCapabilities fetches the service's OpenAPI schema.
+	OpCapabilities = "capabilities"
+)
+
+// Observer receives lifecycle events for every API call made by a Client.
+// It can be used to record metrics or trace spans. Methods are called
+// synchronously and must be safe for concurrent use.
+type Observer interface {
+	// OnRequestStart is called once before the first attempt of a call. The
+	// returned context is used for the call and passed to the other methods,
+	// so it can carry a trace span.
+	OnRequestStart(ctx context.Context, info RequestInfo) context.Context
+	// OnRetry is called before each retry of a failed attempt.
+	OnRetry(ctx context.Context, info RetryInfo)
+	// OnResponse is called once when the call succeeds.
+	OnResponse(ctx context.Context, info ResponseInfo)
+	// OnError is called once when the call fails.
+	OnError(ctx context.Context, info ResponseInfo, err error)
+}
+
+// RequestInfo describes an API call.
+type RequestInfo struct {
+	// Operation is one of OpHealthCheck, OpExtract, OpExtractGCS or
+	// OpCapabilities.
+	Operation string
+	Method    string
+	URL       string
+	// FileName is the uploaded file name, or the input GCS URL for
+	// OpExtractGCS.
+	FileName string
+}
+
+// RetryInfo describes a retry of a failed attempt.
+type RetryInfo struct {
+	RequestInfo
+	// Attempt is the number of the attempt that failed.
+	Attempt int
+	Delay   time.Duration
+	Err     error
+}
+
+// ResponseInfo describes the outcome of an API call.
+type ResponseInfo struct {
+	RequestInfo
+	Attempts int
+	// CacheHit reports that the result was served by WithCache without
+	// contacting the service, so there were no attempts.
+	CacheHit bool
+	// StatusCode is the HTTP status of the last attempt, or zero if no
+	// response was received.
+	StatusCode    int
+	BytesSent     int64
+	BytesReceived int64
+	// PageCount is the number of pages extracted by a successful extraction.
+	PageCount int
+	// QueueTime is the time spent waiting for WithMaxConcurrent or
+	// WithMaxInFlightBytes, summed over attempts.
+	QueueTime time.Duration
+	// Duration is the time taken by the whole call, including retries and
+	// queueing.
+	Duration time.Duration
+}
+
+// WithObserver adds an observer of the client's API calls.
+func WithObserver(observer Observer) ClientOption {
+	return func(c *Client) {
+		c.Observers = append(c.Observers, observer)
+	}
+}
+
+// attemptStats reports the transfer of a single attempt.
+type attemptStats struct {
+	statusCode    int
+	bytesSent     int64
+	bytesReceived int64
+}
+
+func (c *Client) observers() []Observer {
+	if c.stats == nil {
+		return c.Observers
+	}
+	return append([]Observer{c.stats}, c.Observers...)
+}
+
+func pageCount(out any) int {
+	switch v := out.(type) {
+	case *TextExtractionResponse:
+		return v.PageCount
+	case *GCSExtractionResponse:
+		return v.PageCount
+	}
+	return 0
+}
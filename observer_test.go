@@ -0,0 +1,189 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+type ctxKey struct{}
+
+type recordingObserver struct {
+	mu        sync.Mutex
+	events    []string
+	retries   []pdfclient.RetryInfo
+	responses []pdfclient.ResponseInfo
+	errs      []error
+	ctxValues []any
+}
+
+func (o *recordingObserver) OnRequestStart(ctx context.Context, info pdfclient.RequestInfo) context.Context {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, "start")
+	return context.WithValue(ctx, ctxKey{}, info.Operation)
+}
+
+func (o *recordingObserver) OnRetry(ctx context.Context, info pdfclient.RetryInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, "retry")
+	o.retries = append(o.retries, info)
+	o.ctxValues = append(o.ctxValues, ctx.Value(ctxKey{}))
+}
+
+func (o *recordingObserver) OnResponse(ctx context.Context, info pdfclient.ResponseInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, "response")
+	o.responses = append(o.responses, info)
+	o.ctxValues = append(o.ctxValues, ctx.Value(ctxKey{}))
+}
+
+func (o *recordingObserver) OnError(ctx context.Context, info pdfclient.ResponseInfo, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, "error")
+	o.responses = append(o.responses, info)
+	o.errs = append(o.errs, err)
+	o.ctxValues = append(o.ctxValues, ctx.Value(ctxKey{}))
+}
+
+func TestObserver_Lifecycle(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithPages("report.pdf", "one", "two"),
+		pdfclienttest.WithFault(pdfclienttest.ServerError(503), pdfclienttest.OnFirst(1)),
+	)
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithObserver(observer),
+		pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "report.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	want := []string{"start", "retry", "response"}
+	if len(observer.events) != len(want) {
+		t.Fatalf("observer events = %v, want %v", observer.events, want)
+	}
+	for i := range want {
+		if observer.events[i] != want[i] {
+			t.Errorf("observer events = %v, want %v", observer.events, want)
+		}
+	}
+
+	retry := observer.retries[0]
+	if retry.Attempt != 1 || retry.Operation != pdfclient.OpExtract || retry.FileName != "report.pdf" {
+		t.Errorf("OnRetry() info = %+v, want attempt 1 of extract report.pdf", retry)
+	}
+
+	response := observer.responses[0]
+	if response.Attempts != 2 {
+		t.Errorf("OnResponse() attempts = %v, want %v", response.Attempts, 2)
+	}
+	if response.StatusCode != 200 {
+		t.Errorf("OnResponse() status = %v, want %v", response.StatusCode, 200)
+	}
+	if response.PageCount != 2 {
+		t.Errorf("OnResponse() page count = %v, want %v", response.PageCount, 2)
+	}
+	if response.BytesSent == 0 || response.BytesReceived == 0 {
+		t.Errorf("OnResponse() bytes sent = %v, received = %v, want both counted", response.BytesSent, response.BytesReceived)
+	}
+	if response.URL != server.URL+"/extract" {
+		t.Errorf("OnResponse() URL = %v, want %v", response.URL, server.URL+"/extract")
+	}
+
+	for _, v := range observer.ctxValues {
+		if v != pdfclient.OpExtract {
+			t.Errorf("observer context value = %v, want context from OnRequestStart", v)
+		}
+	}
+}
+
+func TestObserver_Error(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFileError("bad.pdf", 400, pdfclienttest.DetailInvalidPDF))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "bad.pdf")
+	if err == nil {
+		t.Fatal("ExtractTextFromBytes() error = nil, want error")
+	}
+
+	if len(observer.errs) != 1 || !errors.Is(observer.errs[0], pdfclient.ErrInvalidPDF) {
+		t.Fatalf("OnError() errors = %v, want one ErrInvalidPDF", observer.errs)
+	}
+	if observer.responses[0].StatusCode != 400 {
+		t.Errorf("OnError() status = %v, want %v", observer.responses[0].StatusCode, 400)
+	}
+}
+
+func TestClient_Stats(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithPages("report.pdf", "one", "two", "three"),
+		pdfclienttest.WithGCSObject("gs://bucket/missing.pdf"),
+	)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "report.pdf"); err != nil {
+			t.Fatalf("ExtractTextFromBytes() error = %v", err)
+		}
+	}
+	if _, err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	_, _ = client.ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{InputGCSURL: "gs://bucket/other.pdf"})
+
+	stats := client.Stats()
+
+	extract := stats.Operations[pdfclient.OpExtract]
+	if extract.Requests != 3 || extract.Succeeded != 3 || extract.InFlight != 0 {
+		t.Errorf("Stats() extract = %+v, want 3 succeeded requests", extract)
+	}
+	if extract.Pages != 9 {
+		t.Errorf("Stats() extract pages = %v, want %v", extract.Pages, 9)
+	}
+	if extract.Latency.Count != 3 || extract.Latency.Max < extract.Latency.P50 {
+		t.Errorf("Stats() extract latency = %+v, want 3 ordered samples", extract.Latency)
+	}
+
+	gcs := stats.Operations[pdfclient.OpExtractGCS]
+	if gcs.Failed != 1 {
+		t.Errorf("Stats() extract_gcs failed = %v, want %v", gcs.Failed, 1)
+	}
+
+	if stats.Total.Requests != 5 || stats.Total.Succeeded != 4 || stats.Total.Failed != 1 {
+		t.Errorf("Stats() total = %+v, want 5 requests, 4 succeeded, 1 failed", stats.Total)
+	}
+}
+
+func TestClient_StatsWithoutNewClient(t *testing.T) {
+	client := &pdfclient.Client{}
+	if stats := client.Stats(); stats.Total.Requests != 0 {
+		t.Errorf("Stats() total requests = %v, want %v", stats.Total.Requests, 0)
+	}
+}
@@ -0,0 +1,183 @@
+package pdfclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/mhpenta/pypdftotext-client/internal/pdf"
+)
+
+// ErrNoPagesSelected is returned when the pages selected with WithPages are
+// all beyond the end of the document.
+var ErrNoPagesSelected = errors.New("no pages selected")
+
+// pageRange is a range of pages numbered from 1. last is 0 for a range
+// that runs to the end of the document.
+type pageRange struct {
+	first, last int
+}
+
+// parsePageRanges parses a comma-separated list of pages and ranges, such
+// as "1-3,10,20-". A range with no end runs to the end of the document, and
+// one with no start begins at page 1.
+func parsePageRanges(s string) ([]pageRange, error) {
+	var ranges []pageRange
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		first, last, isRange := strings.Cut(item, "-")
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r pageRange
+		var err error
+		switch {
+		case item == "" || isRange && first == "" && last == "":
+			return nil, fmt.Errorf("invalid page range %q: empty range", s)
+		case !isRange:
+			r.first, err = parsePageNumber(first)
+			r.last = r.first
+		default:
+			r.first = 1
+			if first != "" {
+				r.first, err = parsePageNumber(first)
+			}
+			if err == nil && last != "" {
+				r.last, err = parsePageNumber(last)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid page range %q: %w", s, err)
+		}
+		if r.last != 0 && r.last < r.first {
+			return nil, fmt.Errorf("invalid page range %q: %d-%d is reversed", s, r.first, r.last)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func parsePageNumber(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not a page number", s)
+	}
+	return n, nil
+}
+
+// formatPageRanges returns ranges in the syntax parsePageRanges accepts.
+func formatPageRanges(ranges []pageRange) string {
+	items := make([]string, len(ranges))
+	for i, r := range ranges {
+		switch r.last {
+		case r.first:
+			items[i] = strconv.Itoa(r.first)
+		case 0:
+			items[i] = strconv.Itoa(r.first) + "-"
+		default:
+			items[i] = strconv.Itoa(r.first) + "-" + strconv.Itoa(r.last)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// containsPage reports whether page, numbered from 1, is in ranges.
+func containsPage(ranges []pageRange, page int) bool {
+	for _, r := range ranges {
+		if page >= r.first && (r.last == 0 || page <= r.last) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectPages returns the zero-based indexes of the pages in ranges of a
+// document of count pages, in ascending order.
+func selectPages(ranges []pageRange, count int) []int {
+	var indexes []int
+	for page := 1; page <= count; page++ {
+		if containsPage(ranges, page) {
+			indexes = append(indexes, page-1)
+		}
+	}
+	return indexes
+}
+
+// supportsPageRanges reports whether the service can extract a page range
+// itself.
+func (c *Client) supportsPageRanges(ctx context.Context) bool {
+	capabilities, err := c.Capabilities(ctx)
+	if err != nil {
+		c.logger().LogAttrs(ctx, slog.LevelDebug, "pdfclient capabilities unavailable",
+			slog.Any("error", err))
+		return false
+	}
+	return capabilities.PageRanges
+}
+
+// extractPages extracts the pages of reader in ranges for a service that
+// cannot select them, by uploading a PDF holding only those pages.
+func (c *Client) extractPages(ctx context.Context, reader io.Reader, fileName string, ranges []pageRange, options callOptions) (*TextExtractionResponse, error) {
+	options.pages = ""
+
+	r, size, err := readerAt(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := pdf.Open(r, size)
+	if err != nil {
+		return nil, preflightError(err)
+	}
+
+	if doc.Encrypted() {
+		// Encrypted files cannot be rewritten, so every page is extracted
+		// and the others are dropped.
+		response, err := c.extractFromReader(ctx, io.NewSectionReader(r, 0, size), fileName, options)
+		if err != nil {
+			return nil, err
+		}
+		result := *response
+		result.Pages = make([]PageData, 0, len(response.Pages))
+		for _, page := range response.Pages {
+			if containsPage(ranges, page.Page) {
+				result.Pages = append(result.Pages, page)
+			}
+		}
+		return &result, nil
+	}
+
+	pages, err := doc.Pages()
+	if err != nil {
+		return nil, preflightError(err)
+	}
+	indexes := selectPages(ranges, len(pages))
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("%w: %s has %d pages", ErrNoPagesSelected, fileName, len(pages))
+	}
+
+	var trimmed bytes.Buffer
+	if err := doc.WritePages(&trimmed, indexes); err != nil {
+		return nil, fmt.Errorf("error trimming PDF: %w", err)
+	}
+	response, err := c.extractFromReader(ctx, bytes.NewReader(trimmed.Bytes()), fileName, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := *response
+	result.Pages = make([]PageData, len(response.Pages))
+	for i, page := range response.Pages {
+		if page.Page >= 1 && page.Page <= len(indexes) {
+			page.Page = indexes[page.Page-1] + 1
+		}
+		result.Pages[i] = page
+	}
+	result.PageCount = len(pages)
+	result.FileSize = int(size)
+	return &result, nil
+}
@@ -0,0 +1,186 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestWithPages_Server(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithPageRanges(),
+		pdfclienttest.WithPages("report.pdf", "one", "two", "three", "four"),
+	)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	data := readFixture(t)
+	response, err := client.ExtractTextFromBytes(context.Background(), data, "report.pdf", pdfclient.WithPages(" 2 - 3 "))
+	if err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	if len(response.Pages) != 2 || response.Pages[0].Page != 2 || response.Pages[1].Text != "three" {
+		t.Errorf("ExtractTextFromBytes() pages = %+v, want pages 2 and 3", response.Pages)
+	}
+	request, _ := server.LastRequest()
+	if got := request.Form.Get("pages"); got != "2-3" {
+		t.Errorf("request pages = %q, want %q", got, "2-3")
+	}
+	if !bytes.Equal(request.File, data) {
+		t.Errorf("request uploaded %d bytes, want the whole file of %d", len(request.File), len(data))
+	}
+}
+
+func TestWithPages_Local(t *testing.T) {
+	var uploads atomic.Int32
+	server := newSplitServer(t, 0, &uploads)
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	response, err := client.ExtractTextFromFile(context.Background(), writeLargePDF(t, 10), pdfclient.WithPages("9-,2,5-6"))
+	if err != nil {
+		t.Fatalf("ExtractTextFromFile() error = %v", err)
+	}
+
+	if n := uploads.Load(); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+	if response.PageCount != 10 {
+		t.Errorf("ExtractTextFromFile() PageCount = %v, want %v", response.PageCount, 10)
+	}
+	want := []int{2, 5, 6, 9, 10}
+	if len(response.Pages) != len(want) {
+		t.Fatalf("ExtractTextFromFile() returned %d pages, want %d", len(response.Pages), len(want))
+	}
+	for i, page := range response.Pages {
+		if text := fmt.Sprintf("page-%d", want[i]); page.Page != want[i] || page.Text != text {
+			t.Errorf("ExtractTextFromFile() Pages[%d] = %+v, want page %d %q", i, page, want[i], text)
+		}
+	}
+}
+
+func TestWithPages_NoPagesSelected(t *testing.T) {
+	var uploads atomic.Int32
+	server := newSplitServer(t, 0, &uploads)
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ExtractTextFromFile(context.Background(), writeLargePDF(t, 3), pdfclient.WithPages("4-"))
+	if !errors.Is(err, pdfclient.ErrNoPagesSelected) {
+		t.Errorf("ExtractTextFromFile() error = %v, want ErrNoPagesSelected", err)
+	}
+	if n := uploads.Load(); n != 0 {
+		t.Errorf("server received %d uploads, want %d", n, 0)
+	}
+}
+
+func TestWithPages_Invalid(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithPageRanges())
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for _, pages := range []string{"0", "3-1", "1,,2", "a-b", "-"} {
+		_, err := client.ExtractTextFromBytes(context.Background(), readFixture(t), "report.pdf", pdfclient.WithPages(pages))
+		if err == nil || !strings.Contains(err.Error(), "invalid page range") {
+			t.Errorf("WithPages(%q) error = %v, want an invalid page range error", pages, err)
+		}
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("server received %d requests, want %d", n, 0)
+	}
+}
+
+func TestWithPages_GCS(t *testing.T) {
+	for _, pageRanges := range []bool{true, false} {
+		t.Run(fmt.Sprintf("PageRanges=%v", pageRanges), func(t *testing.T) {
+			options := []pdfclienttest.Option{pdfclienttest.WithGCSObject("gs://bucket/doc.pdf", "one", "two", "three")}
+			if pageRanges {
+				options = append(options, pdfclienttest.WithPageRanges())
+			}
+			server := pdfclienttest.NewServer(options...)
+			defer server.Close()
+
+			client, err := pdfclient.NewClient(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			response, err := client.ExtractTextFromGCS(context.Background(),
+				pdfclient.GCSExtractionRequest{InputGCSURL: "gs://bucket/doc.pdf", Pages: "1"},
+				pdfclient.WithPages("2-"))
+			if err != nil {
+				t.Fatalf("ExtractTextFromGCS() error = %v", err)
+			}
+
+			if len(response.Pages) != 2 || response.Pages[0].Page != 2 || response.Pages[1].Text != "three" {
+				t.Errorf("ExtractTextFromGCS() pages = %+v, want pages 2 and 3", response.Pages)
+			}
+			if response.PageCount != 3 {
+				t.Errorf("ExtractTextFromGCS() PageCount = %v, want %v", response.PageCount, 3)
+			}
+
+			request, _ := server.LastRequest()
+			var sent pdfclient.GCSExtractionRequest
+			if err := json.Unmarshal(request.Body, &sent); err != nil {
+				t.Fatalf("Failed to decode request: %v", err)
+			}
+			want := ""
+			if pageRanges {
+				want = "2-"
+			}
+			if sent.Pages != want {
+				t.Errorf("request pages = %q, want %q", sent.Pages, want)
+			}
+		})
+	}
+}
+
+func TestExtractTextLarge_WithPages(t *testing.T) {
+	var uploads atomic.Int32
+	server := newSplitServer(t, 0, &uploads)
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	response, err := client.ExtractTextLarge(context.Background(), writeLargePDF(t, 23), pdfclient.SplitOptions{
+		PagesPerPart: 5,
+		CallOptions:  []pdfclient.CallOption{pdfclient.WithPages("3-14")},
+	})
+	if err != nil {
+		t.Fatalf("ExtractTextLarge() error = %v", err)
+	}
+
+	if n := uploads.Load(); n != 3 {
+		t.Errorf("server received %d uploads, want %d", n, 3)
+	}
+	if response.PageCount != 23 || len(response.Pages) != 12 {
+		t.Fatalf("ExtractTextLarge() PageCount = %v, %d pages, want %v, %d", response.PageCount, len(response.Pages), 23, 12)
+	}
+	for i, page := range response.Pages {
+		if want := fmt.Sprintf("page-%d", i+3); page.Page != i+3 || page.Text != want {
+			t.Errorf("ExtractTextLarge() Pages[%d] = %+v, want page %d %q", i, page, i+3, want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package pdfclienttest
+
+import (
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Fault is a misbehaviour of the extraction service that the fake server
+// can inject into its responses. Faults are added with WithFault or
+// Server.AddFault and selected with OnPath, OnRequests, OnFirst and OnEvery.
+type Fault struct {
+	name       string
+	delay      time.Duration
+	statusCode int
+	header     http.Header
+	body       any
+	truncate   bool
+	reset      bool
+	dripSize   int
+	dripDelay  time.Duration
+	terminates bool
+}
+
+// String returns the name of the fault as recorded in Request.Fault.
+func (f Fault) String() string {
+	return f.name
+}
+
+// Latency delays the response by d and then serves the request normally.
+// Latency can be combined with any other fault.
+func Latency(d time.Duration) Fault {
+	return Fault{name: "latency", delay: d}
+}
+
+// TooManyRequests responds with 429 and a Retry-After header of retryAfter,
+// rounded up to whole seconds. No header is sent if retryAfter is zero.
+func TooManyRequests(retryAfter time.Duration) Fault {
+	header := http.Header{}
+	if retryAfter > 0 {
+		header.Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	return Fault{
+		name:       "too_many_requests",
+		statusCode: http.StatusTooManyRequests,
+		header:     header,
+		body:       map[string]string{"detail": "Too many requests"},
+		terminates: true,
+	}
+}
+
+// ServerError responds with statusCode, typically 500, 502, 503 or 504.
+func ServerError(statusCode int) Fault {
+	return Fault{
+		name:       "server_error_" + strconv.Itoa(statusCode),
+		statusCode: statusCode,
+		body:       map[string]string{"detail": http.StatusText(statusCode)},
+		terminates: true,
+	}
+}
+
+// TruncatedBody serves the request normally but drops the connection half
+// way through the response body, after announcing its full length.
+func TruncatedBody() Fault {
+	return Fault{name: "truncated_body", truncate: true, terminates: true}
+}
+
+// ResetConnection reads part of the request body and then resets the TCP
+// connection without responding.
+func ResetConnection() Fault {
+	return Fault{name: "reset_connection", reset: true, terminates: true}
+}
+
+// SlowDrip serves the request normally but writes the response body in
+// chunks of size bytes, pausing for interval between chunks.
+func SlowDrip(size int, interval time.Duration) Fault {
+	return Fault{name: "slow_drip", dripSize: max(size, 1), dripDelay: interval, terminates: true}
+}
+
+// ValidationError responds with a FastAPI 422 payload reporting msg for the
+// body field named field, for example "method".
+func ValidationError(field, msg, errType string) Fault {
+	return Fault{
+		name:       "validation_error",
+		statusCode: http.StatusUnprocessableEntity,
+		body: map[string]any{"detail": []validationError{{
+			Loc:  []any{"body", field},
+			Msg:  msg,
+			Type: errType,
+		}}},
+		terminates: true,
+	}
+}
+
+// A Selector restricts the requests a fault applies to.
+type Selector func(*faultRule)
+
+// OnPath applies the fault only to requests for path, such as "/extract".
+// Request numbers given to other selectors then count only these requests.
+func OnPath(path string) Selector {
+	return func(r *faultRule) {
+		r.path = path
+	}
+}
+
+// OnRequests applies the fault to the given (1-based) matching requests.
+func OnRequests(n ...int) Selector {
+	return func(r *faultRule) {
+		r.match = func(count int) bool { return slices.Contains(n, count) }
+	}
+}
+
+// OnFirst applies the fault to the first n matching requests, producing a
+// burst of failures followed by recovery.
+func OnFirst(n int) Selector {
+	return func(r *faultRule) {
+		r.match = func(count int) bool { return count <= n }
+	}
+}
+
+// OnEvery applies the fault to every nth matching request.
+func OnEvery(n int) Selector {
+	return func(r *faultRule) {
+		r.match = func(count int) bool { return n > 0 && count%n == 0 }
+	}
+}
+
+type faultRule struct {
+	fault Fault
+	path  string
+	match func(count int) bool
+	count int
+}
+
+// WithFault injects fault into the responses selected by selectors. Without
+// selectors the fault applies to every request.
+func WithFault(fault Fault, selectors ...Selector) Option {
+	return func(s *Server) {
+		s.faults = append(s.faults, newFaultRule(fault, selectors))
+	}
+}
+
+// AddFault injects fault into subsequent responses selected by selectors.
+func (s *Server) AddFault(fault Fault, selectors ...Selector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, newFaultRule(fault, selectors))
+}
+
+// ClearFaults removes all injected faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+func newFaultRule(fault Fault, selectors []Selector) *faultRule {
+	rule := &faultRule{fault: fault}
+	for _, selector := range selectors {
+		selector(rule)
+	}
+	return rule
+}
+
+// selectFaults returns the total latency and the first terminating fault to
+// inject into a request for path.
+func (s *Server) selectFaults(path string) (time.Duration, *Fault, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		delay    time.Duration
+		terminal *Fault
+		names    []string
+	)
+
+	for _, rule := range s.faults {
+		if rule.path != "" && rule.path != path {
+			continue
+		}
+		rule.count++
+		if rule.match != nil && !rule.match(rule.count) {
+			continue
+		}
+		if rule.fault.terminates {
+			if terminal == nil {
+				terminal = &rule.fault
+				names = append(names, rule.fault.name)
+			}
+			continue
+		}
+		delay += rule.fault.delay
+		names = append(names, rule.fault.name)
+	}
+
+	return delay, terminal, names
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	delay, fault, names := s.selectFaults(r.URL.Path)
+	name := strings.Join(names, ",")
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			s.record(Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Fault: name})
+			return
+		case <-timer.C:
+		}
+	}
+
+	if fault == nil {
+		s.handle(w, r, name)
+		return
+	}
+
+	switch {
+	case fault.reset:
+		s.record(Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Fault: name})
+		resetConnection(w, r)
+
+	case fault.truncate, fault.dripSize > 0:
+		recorder := httptest.NewRecorder()
+		s.handle(recorder, r, name)
+		body := recorder.Body.Bytes()
+
+		for key, values := range recorder.Header() {
+			w.Header()[key] = values
+		}
+		if fault.truncate {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		w.WriteHeader(recorder.Code)
+
+		if fault.truncate {
+			_, _ = w.Write(body[:len(body)/2])
+			return
+		}
+		dripWrite(w, r, body, fault.dripSize, fault.dripDelay)
+
+	default:
+		_, _ = io.Copy(io.Discard, r.Body)
+		s.record(Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Fault: name})
+		for key, values := range fault.header {
+			w.Header()[key] = values
+		}
+		writeJSON(w, fault.statusCode, fault.body)
+	}
+}
+
+// resetConnection reads part of the request body and then closes the
+// underlying connection with a TCP reset.
+func resetConnection(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.CopyN(io.Discard, r.Body, 1024)
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("pdfclienttest: ResetConnection requires a connection that can be hijacked")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
+
+func dripWrite(w http.ResponseWriter, r *http.Request, body []byte, size int, interval time.Duration) {
+	flusher, _ := w.(http.Flusher)
+	for len(body) > 0 {
+		n := min(size, len(body))
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+		if len(body) == 0 {
+			return
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package pdfclienttest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+var fastRetries = pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Millisecond,
+})
+
+func TestFault_TooManyRequestsBurst(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.TooManyRequests(0), pdfclienttest.OnPath("/extract"), pdfclienttest.OnFirst(2)),
+	)
+	defer server.Close()
+
+	client := newClient(t, server, fastRetries)
+
+	if _, err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v, fault should only apply to /extract", err)
+	}
+
+	if _, err := client.ExtractTextFromBytes(context.Background(), []byte(validPDF), "report.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	var faults []string
+	for _, request := range server.Requests() {
+		if request.Path == "/extract" {
+			faults = append(faults, request.Fault)
+		}
+	}
+
+	if strings.Join(faults, " ") != "too_many_requests too_many_requests " {
+		t.Errorf("recorded faults = %q, want two 429s followed by success", faults)
+	}
+}
+
+func TestFault_RetryAfterHeader(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.TooManyRequests(1500 * time.Millisecond)),
+	)
+	defer server.Close()
+
+	_, err := newClient(t, server).HealthCheck(context.Background())
+
+	var clientErr pdfclient.ClientError
+	if !errors.As(err, &clientErr) || clientErr.RetryAfter != 2*time.Second {
+		t.Errorf("HealthCheck() error = %v (RetryAfter %v), want 429 with 2s Retry-After", err, clientErr.RetryAfter)
+	}
+}
+
+func TestFault_ResetConnectionIsRetried(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.ResetConnection(), pdfclienttest.OnRequests(1)),
+	)
+	defer server.Close()
+
+	client := newClient(t, server, fastRetries)
+
+	if _, err := client.ExtractTextFromBytes(context.Background(), []byte(validPDF), "report.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	if got := len(server.Requests()); got != 2 {
+		t.Errorf("Requests() = %v, want %v", got, 2)
+	}
+}
+
+func TestFault_ServerErrorEveryOtherRequest(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.ServerError(http.StatusBadGateway), pdfclienttest.OnEvery(2)),
+	)
+	defer server.Close()
+
+	client := newClient(t, server)
+
+	for i, wantErr := range []bool{false, true, false, true} {
+		_, err := client.HealthCheck(context.Background())
+		if (err != nil) != wantErr {
+			t.Errorf("request %d error = %v, wantErr %v", i+1, err, wantErr)
+		}
+	}
+}
+
+func TestFault_TruncatedBody(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithFault(pdfclienttest.TruncatedBody()))
+	defer server.Close()
+
+	_, err := newClient(t, server).ExtractTextFromBytes(context.Background(), []byte(validPDF), "report.pdf")
+	if !errors.Is(err, pdfclient.ErrNetwork) {
+		t.Errorf("ExtractTextFromBytes() error = %v, want ErrNetwork", err)
+	}
+}
+
+func TestFault_SlowDripAndLatencyHitTimeout(t *testing.T) {
+	for name, fault := range map[string]pdfclienttest.Fault{
+		"slow drip": pdfclienttest.SlowDrip(4, 50*time.Millisecond),
+		"latency":   pdfclienttest.Latency(time.Second),
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := pdfclienttest.NewServer(pdfclienttest.WithFault(fault))
+			defer server.Close()
+
+			client := newClient(t, server, pdfclient.WithTimeout(100*time.Millisecond))
+
+			start := time.Now()
+			if _, err := client.HealthCheck(context.Background()); err == nil {
+				t.Fatal("HealthCheck() error = nil, want timeout")
+			}
+			if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+				t.Errorf("HealthCheck() took %v, want it to time out after ~100ms", elapsed)
+			}
+		})
+	}
+}
+
+func TestFault_ValidationError(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFault(pdfclienttest.ValidationError("method", "value is not a valid enumeration member", "type_error.enum")),
+	)
+	defer server.Close()
+
+	_, err := newClient(t, server).ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{
+		InputGCSURL: "gs://bucket/report.pdf",
+	})
+
+	var clientErr pdfclient.ClientError
+	if !errors.As(err, &clientErr) || clientErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("ExtractTextFromGCS() error = %v, want HTTP 422", err)
+	}
+
+	if !strings.Contains(clientErr.Message, "value is not a valid enumeration member") {
+		t.Errorf("ClientError.Message = %q, want FastAPI validation payload", clientErr.Message)
+	}
+}
@@ -0,0 +1,77 @@
+package pdfclienttest
+
+// openAPISchema returns the part of the OpenAPI schema FastAPI generates
+// for the real service that describes its endpoints and their options.
+// pageRanges adds the pages option.
+func openAPISchema(version string, pageRanges bool) map[string]any {
+	ref := func(name string) map[string]any {
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	body := func(contentType, schema string) map[string]any {
+		return map[string]any{
+			"content": map[string]any{
+				contentType: map[string]any{"schema": ref(schema)},
+			},
+			"required": true,
+		}
+	}
+	options := func(properties map[string]any) map[string]any {
+		properties["method"] = map[string]any{"allOf": []any{ref("ExtractionMethod")}, "default": "auto"}
+		properties["output_format"] = map[string]any{"allOf": []any{ref("OutputFormat")}, "default": "text"}
+		if pageRanges {
+			properties["pages"] = map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "null"}}}
+		}
+		return properties
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info":    map[string]any{"title": "PDF Text Extraction API", "version": version},
+		"paths": map[string]any{
+			"/health": map[string]any{
+				"get": map[string]any{"summary": "Health Check"},
+			},
+			"/extract": map[string]any{
+				"post": map[string]any{
+					"summary":     "Extract Text",
+					"requestBody": body("multipart/form-data", "Body_extract_text_extract_post"),
+				},
+			},
+			"/extract-from-gcs": map[string]any{
+				"post": map[string]any{
+					"summary":     "Extract From Gcs",
+					"requestBody": body("application/json", "GCSExtractionRequest"),
+				},
+			},
+		},
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Body_extract_text_extract_post": map[string]any{
+					"type":     "object",
+					"required": []any{"file"},
+					"properties": options(map[string]any{
+						"file": map[string]any{"type": "string", "format": "binary"},
+					}),
+				},
+				"GCSExtractionRequest": map[string]any{
+					"type":     "object",
+					"required": []any{"input_gcs_url"},
+					"properties": options(map[string]any{
+						"input_gcs_url":  map[string]any{"type": "string"},
+						"output_gcs_url": map[string]any{"anyOf": []any{map[string]any{"type": "string"}, map[string]any{"type": "null"}}},
+					}),
+				},
+				"ExtractionMethod": map[string]any{"type": "string", "enum": stringsToAny(methods)},
+				"OutputFormat":     map[string]any{"type": "string", "enum": stringsToAny(outputFormats)},
+			},
+		},
+	}
+}
+
+func stringsToAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
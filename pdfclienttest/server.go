@@ -0,0 +1,554 @@
+// Package pdfclienttest provides an in-process fake of the PDF Text
+// Extraction API for use in tests.
+//
+// The fake implements /health, /openapi.json, /extract and /extract-from-gcs
+// with the same response shapes and error details as the real service, so
+// the ClientError helpers of pdfclient classify its errors exactly as they
+// would in production:
+//
+//	server := pdfclienttest.NewServer(
+//		pdfclienttest.WithPages("report.pdf", "page one", "page two"),
+//		pdfclienttest.WithAPIKey("secret"),
+//	)
+//	defer server.Close()
+//
+//	client, _ := pdfclient.NewClient(server.URL, pdfclient.WithAPIKey("secret"))
+package pdfclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+// Error details returned by the fake. They match the messages of the real
+// service that the pdfclient.ClientError helpers look for.
+const (
+	DetailInvalidPDF       = "Invalid PDF format: file does not appear to be a valid PDF"
+	DetailTruncatedPDF     = "PDF file is incomplete or truncated"
+	DetailUnauthorized     = "Invalid or missing API key"
+	DetailNotFound         = "Not Found"
+	DetailMethodNotAllowed = "Method Not Allowed"
+)
+
+// DetailFileTooLarge returns the detail sent when an upload exceeds limit
+// bytes.
+func DetailFileTooLarge(limit int64) string {
+	return fmt.Sprintf("File too large. Maximum size is %d bytes", limit)
+}
+
+// DetailGCSPermissionDenied returns the detail sent when the service may not
+// read gcsURL.
+func DetailGCSPermissionDenied(gcsURL string) string {
+	return fmt.Sprintf("Permission denied accessing %s", gcsURL)
+}
+
+// DetailGCSNotFound returns the detail sent when gcsURL does not exist.
+func DetailGCSNotFound(gcsURL string) string {
+	return fmt.Sprintf("Blob does not exist: %s", gcsURL)
+}
+
+const defaultVersion = "1.0.0"
+
+// methods and outputFormats are the values accepted for the method and
+// output_format fields.
+var (
+	methods       = []string{"auto", "pypdf2", "pdfplumber"}
+	outputFormats = []string{"text", "json"}
+)
+
+// Request is a request received by the fake server.
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+
+	// FileName and File are the name and contents of the uploaded file for
+	// /extract requests.
+	FileName string
+	File     []byte
+	// Form holds the non-file multipart fields of /extract requests.
+	Form url.Values
+
+	// Body is the raw request body of requests other than /extract.
+	Body []byte
+
+	// Fault names the fault injected into the response, if any.
+	Fault string
+}
+
+// Server is a fake extraction service listening on a local address.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	version     string
+	apiKey      string
+	faults      []*faultRule
+	maxFileSize int64
+	pageRanges  bool
+	files       map[string][]string
+	fileErrors  map[string]fileError
+	gcsObjects  map[string][]string
+	gcsDenied   map[string]bool
+	requests    []Request
+}
+
+type fileError struct {
+	statusCode int
+	detail     string
+}
+
+type Option func(*Server)
+
+// WithVersion sets the version reported by /health.
+func WithVersion(version string) Option {
+	return func(s *Server) {
+		s.version = version
+	}
+}
+
+// WithAPIKey makes the server reject extraction requests whose X-API-Key
+// header does not match key. /health does not require a key.
+func WithAPIKey(key string) Option {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithMaxFileSize makes the server reject uploads larger than limit bytes.
+func WithMaxFileSize(limit int64) Option {
+	return func(s *Server) {
+		s.maxFileSize = limit
+	}
+}
+
+// WithPageRanges makes the server accept the pages field, extracting only
+// the pages it selects, and list it in /openapi.json.
+func WithPageRanges() Option {
+	return func(s *Server) {
+		s.pageRanges = true
+	}
+}
+
+// WithPages sets the text of each page returned for uploads named fileName.
+// Uploads without canned pages return a single page naming the file.
+func WithPages(fileName string, pages ...string) Option {
+	return func(s *Server) {
+		s.files[fileName] = pages
+	}
+}
+
+// WithFileError makes uploads named fileName fail with the given status code
+// and detail.
+func WithFileError(fileName string, statusCode int, detail string) Option {
+	return func(s *Server) {
+		s.fileErrors[fileName] = fileError{statusCode: statusCode, detail: detail}
+	}
+}
+
+// WithGCSObject adds a PDF at gcsURL whose pages have the given text. Other
+// GCS URLs are reported as not found.
+func WithGCSObject(gcsURL string, pages ...string) Option {
+	return func(s *Server) {
+		s.gcsObjects[gcsURL] = pages
+	}
+}
+
+// WithGCSPermissionDenied makes requests reading or writing gcsURL fail with
+// a permission error.
+func WithGCSPermissionDenied(gcsURL string) Option {
+	return func(s *Server) {
+		s.gcsDenied[gcsURL] = true
+	}
+}
+
+// NewServer starts and returns a new fake server. The caller should call
+// Close when finished.
+func NewServer(options ...Option) *Server {
+	s := &Server{
+		version:    defaultVersion,
+		files:      make(map[string][]string),
+		fileErrors: make(map[string]fileError),
+		gcsObjects: make(map[string][]string),
+		gcsDenied:  make(map[string]bool),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Requests returns the requests received so far, in arrival order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// LastRequest returns the most recently received request.
+func (s *Server) LastRequest() (Request, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.requests) == 0 {
+		return Request{}, false
+	}
+	return s.requests[len(s.requests)-1], true
+}
+
+func (s *Server) record(r Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r)
+}
+
+// handle serves r as the real service would. fault names the fault being
+// injected, if any, and is recorded with the request.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, fault string) {
+	recorded := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Fault:  fault,
+	}
+
+	switch r.URL.Path {
+	case "/health":
+		s.record(recorded)
+		if r.Method != http.MethodGet {
+			writeDetail(w, http.StatusMethodNotAllowed, DetailMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, pdfclient.HealthResponse{Status: "ok", Version: s.version})
+
+	case "/openapi.json":
+		s.record(recorded)
+		if r.Method != http.MethodGet {
+			writeDetail(w, http.StatusMethodNotAllowed, DetailMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, openAPISchema(s.version, s.pageRanges))
+
+	case "/extract":
+		s.handleExtract(w, r, recorded)
+
+	case "/extract-from-gcs":
+		recorded.Body, _ = io.ReadAll(r.Body)
+		s.record(recorded)
+		if r.Method != http.MethodPost {
+			writeDetail(w, http.StatusMethodNotAllowed, DetailMethodNotAllowed)
+			return
+		}
+		if !s.authorized(w, r) {
+			return
+		}
+		s.handleGCS(w, recorded.Body)
+
+	default:
+		s.record(recorded)
+		writeDetail(w, http.StatusNotFound, DetailNotFound)
+	}
+}
+
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if s.apiKey != "" && r.Header.Get("X-API-Key") != s.apiKey {
+		writeDetail(w, http.StatusUnauthorized, DetailUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleExtract(w http.ResponseWriter, r *http.Request, recorded Request) {
+	if r.Method != http.MethodPost {
+		s.record(recorded)
+		writeDetail(w, http.StatusMethodNotAllowed, DetailMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseMultipartForm(32 << 20)
+	if err == nil {
+		recorded.Form = url.Values(r.MultipartForm.Value)
+		if file, header, fileErr := r.FormFile("file"); fileErr == nil {
+			recorded.FileName = header.Filename
+			recorded.File, _ = io.ReadAll(file)
+			_ = file.Close()
+		}
+	}
+	s.record(recorded)
+
+	if !s.authorized(w, r) {
+		return
+	}
+
+	if err != nil || recorded.FileName == "" {
+		writeValidationError(w, validationError{
+			Loc:  []any{"body", "file"},
+			Msg:  "field required",
+			Type: "value_error.missing",
+		})
+		return
+	}
+
+	if errs := validateOptions(recorded.Form.Get("method"), recorded.Form.Get("output_format"), "body"); len(errs) > 0 {
+		writeValidationError(w, errs...)
+		return
+	}
+
+	if s.maxFileSize > 0 && int64(len(recorded.File)) > s.maxFileSize {
+		writeDetail(w, http.StatusRequestEntityTooLarge, DetailFileTooLarge(s.maxFileSize))
+		return
+	}
+
+	if fe, ok := s.fileErrors[recorded.FileName]; ok {
+		writeDetail(w, fe.statusCode, fe.detail)
+		return
+	}
+
+	if detail := checkPDF(recorded.File); detail != "" {
+		writeDetail(w, http.StatusBadRequest, detail)
+		return
+	}
+
+	texts, ok := s.files[recorded.FileName]
+	if !ok {
+		texts = []string{fmt.Sprintf("Text extracted from %s.", recorded.FileName)}
+	}
+
+	result, err := s.selectPages(texts, recorded.Form.Get("pages"))
+	if err != nil {
+		writeValidationError(w, validationError{
+			Loc:  []any{"body", "pages"},
+			Msg:  err.Error(),
+			Type: "value_error",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pdfclient.TextExtractionResponse{
+		Pages:     result,
+		PageCount: len(texts),
+		FileName:  recorded.FileName,
+		FileSize:  len(recorded.File),
+	})
+}
+
+func (s *Server) handleGCS(w http.ResponseWriter, body []byte) {
+	var request pdfclient.GCSExtractionRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeValidationError(w, validationError{
+			Loc:  []any{"body"},
+			Msg:  "value is not a valid dict",
+			Type: "type_error.dict",
+		})
+		return
+	}
+
+	var errs []validationError
+	if request.InputGCSURL == "" {
+		errs = append(errs, validationError{
+			Loc:  []any{"body", "input_gcs_url"},
+			Msg:  "field required",
+			Type: "value_error.missing",
+		})
+	} else if !strings.HasPrefix(request.InputGCSURL, "gs://") {
+		errs = append(errs, validationError{
+			Loc:  []any{"body", "input_gcs_url"},
+			Msg:  "GCS URL must start with gs://",
+			Type: "value_error",
+		})
+	}
+	if request.OutputGCSURL != nil && !strings.HasPrefix(*request.OutputGCSURL, "gs://") {
+		errs = append(errs, validationError{
+			Loc:  []any{"body", "output_gcs_url"},
+			Msg:  "GCS URL must start with gs://",
+			Type: "value_error",
+		})
+	}
+	errs = append(errs, validateOptions(request.Method, request.OutputFormat, "body")...)
+	if len(errs) > 0 {
+		writeValidationError(w, errs...)
+		return
+	}
+
+	if s.gcsDenied[request.InputGCSURL] {
+		writeDetail(w, http.StatusForbidden, DetailGCSPermissionDenied(request.InputGCSURL))
+		return
+	}
+	if request.OutputGCSURL != nil && s.gcsDenied[*request.OutputGCSURL] {
+		writeDetail(w, http.StatusForbidden, DetailGCSPermissionDenied(*request.OutputGCSURL))
+		return
+	}
+
+	texts, ok := s.gcsObjects[request.InputGCSURL]
+	if !ok {
+		writeDetail(w, http.StatusNotFound, DetailGCSNotFound(request.InputGCSURL))
+		return
+	}
+
+	result, err := s.selectPages(texts, request.Pages)
+	if err != nil {
+		writeValidationError(w, validationError{
+			Loc:  []any{"body", "pages"},
+			Msg:  err.Error(),
+			Type: "value_error",
+		})
+		return
+	}
+
+	method := request.Method
+	if method == "" || method == "auto" {
+		method = "pdfplumber"
+	}
+
+	size := 0
+	for _, text := range texts {
+		size += len(text)
+	}
+
+	writeJSON(w, http.StatusOK, pdfclient.GCSExtractionResponse{
+		Pages:          result,
+		PageCount:      len(texts),
+		FileName:       path.Base(request.InputGCSURL),
+		FileSize:       size,
+		Method:         method,
+		OutputLocation: request.OutputGCSURL,
+	})
+}
+
+// checkPDF returns the error detail for content that is not a complete PDF,
+// or "" if it looks valid.
+func checkPDF(content []byte) string {
+	if !bytes.HasPrefix(content, []byte("%PDF-")) {
+		return DetailInvalidPDF
+	}
+	tail := content[max(0, len(content)-1024):]
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return DetailTruncatedPDF
+	}
+	return ""
+}
+
+func pages(texts []string) []pdfclient.PageData {
+	result := make([]pdfclient.PageData, len(texts))
+	for i, text := range texts {
+		result[i] = pdfclient.PageData{Page: i + 1, Text: text}
+	}
+	return result
+}
+
+// selectPages returns the pages of texts selected by spec, a pages field
+// such as "1-3,10,20-", keeping their numbers in the document. spec is
+// ignored unless the server accepts page ranges.
+func (s *Server) selectPages(texts []string, spec string) ([]pdfclient.PageData, error) {
+	all := pages(texts)
+	if !s.pageRanges || spec == "" {
+		return all, nil
+	}
+
+	selected := make([]bool, len(all))
+	for _, item := range strings.Split(spec, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(item), "-")
+		from, err := pageNumber(first, 1)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = pageNumber(last, len(all)); err != nil {
+				return nil, err
+			}
+		}
+		if !isRange && first == "" || to < from {
+			return nil, fmt.Errorf("invalid page range %q", item)
+		}
+		for page := from; page <= min(to, len(all)); page++ {
+			selected[page-1] = true
+		}
+	}
+
+	var result []pdfclient.PageData
+	for i, page := range all {
+		if selected[i] {
+			result = append(result, page)
+		}
+	}
+	return result, nil
+}
+
+// pageNumber parses a page number of a range, returning def if it is empty.
+func pageNumber(s string, def int) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid page number %q", s)
+	}
+	return n, nil
+}
+
+// validationError is a single entry of a FastAPI 422 response.
+type validationError struct {
+	Loc  []any  `json:"loc"`
+	Msg  string `json:"msg"`
+	Type string `json:"type"`
+}
+
+func validateOptions(method, outputFormat, location string) []validationError {
+	var errs []validationError
+	if method != "" && !slices.Contains(methods, method) {
+		errs = append(errs, validationError{
+			Loc:  []any{location, "method"},
+			Msg:  "value is not a valid enumeration member; permitted: " + quoteAll(methods),
+			Type: "type_error.enum",
+		})
+	}
+	if outputFormat != "" && !slices.Contains(outputFormats, outputFormat) {
+		errs = append(errs, validationError{
+			Loc:  []any{location, "output_format"},
+			Msg:  "value is not a valid enumeration member; permitted: " + quoteAll(outputFormats),
+			Type: "type_error.enum",
+		})
+	}
+	return errs
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func writeValidationError(w http.ResponseWriter, errs ...validationError) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"detail": errs})
+}
+
+func writeDetail(w http.ResponseWriter, statusCode int, detail string) {
+	writeJSON(w, statusCode, map[string]string{"detail": detail})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
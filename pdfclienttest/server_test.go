@@ -0,0 +1,224 @@
+package pdfclienttest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+const validPDF = "%PDF-1.7\n1 0 obj\n<<>>\nendobj\ntrailer\n<<>>\n%%EOF\n"
+
+func newClient(t *testing.T, server *pdfclienttest.Server, options ...pdfclient.ClientOption) *pdfclient.Client {
+	t.Helper()
+	client, err := pdfclient.NewClient(server.URL, options...)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestServer_Extract(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithPages("report.pdf", "page one", "page two"),
+		pdfclienttest.WithAPIKey("secret"),
+	)
+	defer server.Close()
+
+	client := newClient(t, server, pdfclient.WithAPIKey("secret"))
+
+	result, err := client.ExtractTextFromBytes(context.Background(), []byte(validPDF), "report.pdf")
+	if err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	if result.GetFullText() != "page one\n\npage two" {
+		t.Errorf("ExtractTextFromBytes() text = %q", result.GetFullText())
+	}
+
+	if result.PageCount != 2 || result.FileSize != len(validPDF) || result.FileName != "report.pdf" {
+		t.Errorf("ExtractTextFromBytes() = %+v", result)
+	}
+
+	request, ok := server.LastRequest()
+	if !ok {
+		t.Fatal("LastRequest() found no request")
+	}
+
+	if request.FileName != "report.pdf" || string(request.File) != validPDF {
+		t.Errorf("recorded upload = %q (%d bytes)", request.FileName, len(request.File))
+	}
+
+	if request.Header.Get("X-API-Key") != "secret" {
+		t.Errorf("recorded X-API-Key = %q, want %q", request.Header.Get("X-API-Key"), "secret")
+	}
+}
+
+func TestServer_ErrorDetails(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithAPIKey("secret"),
+		pdfclienttest.WithMaxFileSize(int64(len(validPDF))),
+		pdfclienttest.WithFileError("slow.pdf", http.StatusRequestTimeout, "Text extraction timed out"),
+	)
+	defer server.Close()
+
+	client := newClient(t, server, pdfclient.WithAPIKey("secret"))
+
+	tests := []struct {
+		name     string
+		content  string
+		fileName string
+		check    func(pdfclient.ClientError) bool
+	}{
+		{
+			name:     "not a PDF",
+			content:  "<html>",
+			fileName: "page.pdf",
+			check:    pdfclient.ClientError.IsInvalidPDFError,
+		},
+		{
+			name:     "truncated PDF",
+			content:  "%PDF-1.7\n1 0 obj",
+			fileName: "truncated.pdf",
+			check:    pdfclient.ClientError.IsInvalidPDFError,
+		},
+		{
+			name:     "too large",
+			content:  validPDF + " ",
+			fileName: "large.pdf",
+			check:    pdfclient.ClientError.IsFileSizeError,
+		},
+		{
+			name:     "timeout",
+			content:  validPDF,
+			fileName: "slow.pdf",
+			check:    pdfclient.ClientError.IsTimeoutError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ExtractTextFromBytes(context.Background(), []byte(tt.content), tt.fileName)
+
+			var clientErr pdfclient.ClientError
+			if !errors.As(err, &clientErr) {
+				t.Fatalf("ExtractTextFromBytes() error = %v, want ClientError", err)
+			}
+			if !tt.check(clientErr) {
+				t.Errorf("ExtractTextFromBytes() error = %v not classified as %s", err, tt.name)
+			}
+		})
+	}
+
+	unauthorized := newClient(t, server)
+	_, err := unauthorized.ExtractTextFromBytes(context.Background(), []byte(validPDF), "report.pdf")
+
+	var clientErr pdfclient.ClientError
+	if !errors.As(err, &clientErr) || clientErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("ExtractTextFromBytes() without API key error = %v, want HTTP 401", err)
+	}
+}
+
+func TestServer_GCS(t *testing.T) {
+	output := "gs://bucket/output/report.txt"
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithGCSObject("gs://bucket/report.pdf", "first", "second"),
+		pdfclienttest.WithGCSPermissionDenied("gs://private/report.pdf"),
+	)
+	defer server.Close()
+
+	client := newClient(t, server)
+
+	result, err := client.ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{
+		InputGCSURL:  "gs://bucket/report.pdf",
+		OutputGCSURL: &output,
+	})
+	if err != nil {
+		t.Fatalf("ExtractTextFromGCS() error = %v", err)
+	}
+
+	if result.PageCount != 2 || result.FileName != "report.pdf" || result.Method != "pdfplumber" {
+		t.Errorf("ExtractTextFromGCS() = %+v", result)
+	}
+
+	if result.OutputLocation == nil || *result.OutputLocation != output {
+		t.Errorf("ExtractTextFromGCS() outputLocation = %v, want %v", result.OutputLocation, output)
+	}
+
+	request, _ := server.LastRequest()
+	var body map[string]any
+	if err := json.Unmarshal(request.Body, &body); err != nil {
+		t.Fatalf("recorded body %q is not JSON: %v", request.Body, err)
+	}
+	if body["method"] != "auto" || body["output_format"] != "text" {
+		t.Errorf("recorded body = %v, want defaulted method and output_format", body)
+	}
+
+	_, err = client.ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{InputGCSURL: "gs://private/report.pdf"})
+	var clientErr pdfclient.ClientError
+	if !errors.As(err, &clientErr) || !clientErr.IsGCSPermissionError() {
+		t.Errorf("ExtractTextFromGCS() error = %v, want GCS permission error", err)
+	}
+
+	_, err = client.ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{InputGCSURL: "gs://bucket/missing.pdf"})
+	if !errors.As(err, &clientErr) || !clientErr.IsGCSNotFoundError() {
+		t.Errorf("ExtractTextFromGCS() error = %v, want GCS not found error", err)
+	}
+
+	_, err = client.ExtractTextFromGCS(context.Background(), pdfclient.GCSExtractionRequest{
+		InputGCSURL: "gs://bucket/report.pdf",
+		Method:      "ocr",
+	})
+	if !errors.As(err, &clientErr) || clientErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("ExtractTextFromGCS() error = %v, want HTTP 422", err)
+	}
+
+	if got := len(server.Requests()); got != 4 {
+		t.Errorf("Requests() = %v, want %v", got, 4)
+	}
+}
+
+func TestServer_OpenAPIGCSRequest(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("GET /openapi.json error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var doc struct {
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string       `json:"required"`
+				Properties map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("Failed to decode schema: %v", err)
+	}
+
+	// The schema names the fields the client sends.
+	output := "gs://bucket/output.txt"
+	data, _ := json.Marshal(pdfclient.GCSExtractionRequest{InputGCSURL: "gs://bucket/report.pdf", OutputGCSURL: &output})
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Failed to decode request: %v", err)
+	}
+
+	schema := doc.Components.Schemas["GCSExtractionRequest"]
+	for field := range fields {
+		if _, ok := schema.Properties[field]; !ok {
+			t.Errorf("GCSExtractionRequest schema has no property %q", field)
+		}
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "input_gcs_url" {
+		t.Errorf("GCSExtractionRequest schema required = %v, want [input_gcs_url]", schema.Required)
+	}
+}
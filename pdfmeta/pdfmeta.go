@@ -0,0 +1,179 @@
+// Package pdfmeta reads the metadata of PDF files locally, without
+// extracting their text: the page count, PDF version, encryption, tagged-PDF
+// status, and the document information dictionary and XMP metadata.
+//
+// Only the cross-reference data, the trailer and the objects they point to
+// are read, so inspecting a large file is cheap:
+//
+//	meta, err := pdfmeta.ReadFile("report.pdf")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(meta.PageCount, meta.Version, meta.Tagged)
+package pdfmeta
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/mhpenta/pypdftotext-client/internal/pdf"
+)
+
+// Errors returned for files that cannot be read as PDFs.
+var (
+	// ErrNotPDF is returned for files that do not start with a PDF header.
+	ErrNotPDF = pdf.ErrNotPDF
+	// ErrTruncated is returned for files that end before the end-of-file
+	// marker.
+	ErrTruncated = pdf.ErrTruncated
+	// ErrCorrupt is returned, wrapped with a description, for files whose
+	// structure cannot be read.
+	ErrCorrupt = pdf.ErrCorrupt
+)
+
+// Metadata describes a PDF file.
+type Metadata struct {
+	// Version is the PDF version, such as "1.7". A /Version entry in the
+	// document catalog takes precedence over the file header when it is
+	// later.
+	Version string
+	// PageCount is the number of pages. It is 0 for encrypted files whose
+	// page tree cannot be read.
+	PageCount int
+	Encrypted bool
+	// Tagged reports whether the catalog's /MarkInfo dictionary marks the
+	// file as a tagged PDF.
+	Tagged bool
+	// StructTree reports whether the file has a logical structure tree
+	// (/StructTreeRoot).
+	StructTree bool
+	// Repaired reports whether the cross-reference data was damaged, so the
+	// objects had to be located by scanning the file.
+	Repaired bool
+
+	// The document information fields. They are read from the /Info
+	// dictionary, or from the XMP metadata when the dictionary lacks them.
+	// They are empty for encrypted files.
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string
+	Producer     string
+	CreationDate time.Time
+	ModDate      time.Time
+
+	// XMP is the document's XMP metadata packet, or nil if it has none.
+	XMP []byte
+}
+
+// ReadFile reads the metadata of the PDF file at path.
+func ReadFile(path string) (*Metadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading file size: %w", err)
+	}
+	return Read(file, info.Size())
+}
+
+// Read reads the metadata of the PDF in the first size bytes of r.
+func Read(r io.ReaderAt, size int64) (*Metadata, error) {
+	doc, err := pdf.Open(r, size)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := doc.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Metadata{
+		Version:   doc.Version(),
+		Encrypted: doc.Encrypted(),
+		Repaired:  doc.Repaired(),
+	}
+
+	count, err := doc.PageCount()
+	switch {
+	case err == nil:
+		meta.PageCount = count
+	case meta.Encrypted:
+		// The page tree may be in an encrypted object stream.
+	default:
+		return nil, err
+	}
+
+	if v, _ := doc.Resolve(catalog["Version"]); v != nil {
+		if name, ok := v.(pdf.Name); ok && laterVersion(string(name), meta.Version) {
+			meta.Version = string(name)
+		}
+	}
+	if markInfo, _ := doc.Resolve(catalog["MarkInfo"]); markInfo != nil {
+		if d, ok := markInfo.(pdf.Dict); ok {
+			marked, _ := doc.Resolve(d["Marked"])
+			meta.Tagged = marked == true
+		}
+	}
+	if root, _ := doc.Resolve(catalog["StructTreeRoot"]); root != nil {
+		_, meta.StructTree = root.(pdf.Dict)
+	}
+
+	if meta.Encrypted {
+		// Strings and streams are encrypted, and no password is known.
+		return meta, nil
+	}
+
+	if info, _ := doc.Resolve(doc.Trailer()["Info"]); info != nil {
+		if d, ok := info.(pdf.Dict); ok {
+			readInfo(doc, d, meta)
+		}
+	}
+	if obj, _ := doc.Resolve(catalog["Metadata"]); obj != nil {
+		if s, ok := obj.(*pdf.Stream); ok {
+			if data, err := doc.Decode(s); err == nil {
+				meta.XMP = data
+				readXMP(data, meta)
+			}
+		}
+	}
+	return meta, nil
+}
+
+// readInfo sets the fields of meta from a document information dictionary.
+func readInfo(doc *pdf.Reader, info pdf.Dict, meta *Metadata) {
+	text := func(key pdf.Name) string {
+		obj, _ := doc.Resolve(info[key])
+		if s, ok := obj.(pdf.String); ok {
+			return textString(s)
+		}
+		return ""
+	}
+
+	meta.Title = text("Title")
+	meta.Author = text("Author")
+	meta.Subject = text("Subject")
+	meta.Keywords = text("Keywords")
+	meta.Creator = text("Creator")
+	meta.Producer = text("Producer")
+	meta.CreationDate = parseDate(text("CreationDate"))
+	meta.ModDate = parseDate(text("ModDate"))
+}
+
+// laterVersion reports whether the PDF version a is later than b.
+func laterVersion(a, b string) bool {
+	va, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return false
+	}
+	vb, err := strconv.ParseFloat(b, 64)
+	return err != nil || va > vb
+}
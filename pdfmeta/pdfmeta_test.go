@@ -0,0 +1,223 @@
+package pdfmeta_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mhpenta/pypdftotext-client/pdfmeta"
+)
+
+// writePDF returns a PDF with the given objects, numbered from 1, and a
+// trailer with /Root 1 0 R and /Info 2 0 R. When compressed is set, the
+// objects are stored in an object stream and located by a cross-reference
+// stream.
+func writePDF(version string, compressed bool, objects ...string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%%PDF-%s\n", version)
+	trailer := fmt.Sprintf("/Size %d /Root 1 0 R /Info 2 0 R", len(objects)+3)
+
+	if !compressed {
+		offsets := make([]int, len(objects))
+		for i, obj := range objects {
+			offsets[i] = b.Len()
+			fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+		}
+		xref := b.Len()
+		fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+		for _, offset := range offsets {
+			fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+		}
+		fmt.Fprintf(&b, "trailer\n<< %s >>\nstartxref\n%d\n%%%%EOF\n", trailer, xref)
+		return b.Bytes()
+	}
+
+	var header, body bytes.Buffer
+	for i, obj := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	objStm := len(objects) + 1
+	objStmOffset := b.Len()
+	fmt.Fprintf(&b, "%d 0 obj\n<< /Type /ObjStm /N %d /First %d /Length %d >>\nstream\n%s%s\nendstream\nendobj\n",
+		objStm, len(objects), header.Len(), header.Len()+body.Len(), header.String(), body.String())
+
+	xrefOffset := b.Len()
+	var entries []byte
+	entries = append(entries, 0, 0, 0, 0)
+	for i := range objects {
+		entries = append(entries, 2, 0, byte(objStm), byte(i))
+	}
+	entries = append(entries, 1, byte(objStmOffset>>8), byte(objStmOffset), 0)
+	entries = append(entries, 1, byte(xrefOffset>>8), byte(xrefOffset), 0)
+	fmt.Fprintf(&b, "%d 0 obj\n<< /Type /XRef %s /W [1 2 1] /Length %d >>\nstream\n%s\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n",
+		objStm+1, trailer, len(entries), entries, xrefOffset)
+	return b.Bytes()
+}
+
+func read(t *testing.T, data []byte) *pdfmeta.Metadata {
+	t.Helper()
+	meta, err := pdfmeta.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return meta
+}
+
+const xmp = `<?xpacket begin="" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+<rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/" pdf:Keywords="quarterly, filings"/>
+<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title><rdf:Alt><rdf:li xml:lang="x-default">XMP title</rdf:li></rdf:Alt></dc:title>
+<dc:description><rdf:Alt><rdf:li xml:lang="x-default">Quarterly report</rdf:li></rdf:Alt></dc:description>
+<dc:creator><rdf:Seq><rdf:li>Jane Doe</rdf:li><rdf:li>John Doe</rdf:li></rdf:Seq></dc:creator>
+</rdf:Description>
+<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/">
+<xmp:ModifyDate>2023-06-30T12:00:00Z</xmp:ModifyDate>
+</rdf:Description>
+</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+func TestReadFile_Fixture(t *testing.T) {
+	meta, err := pdfmeta.ReadFile("../fixtures/example.pdf")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	if meta.Version != "1.7" {
+		t.Errorf("ReadFile() Version = %v, want %v", meta.Version, "1.7")
+	}
+	if meta.PageCount != 15 {
+		t.Errorf("ReadFile() PageCount = %v, want %v", meta.PageCount, 15)
+	}
+	if !meta.Tagged || !meta.StructTree {
+		t.Errorf("ReadFile() Tagged = %v, StructTree = %v, want true", meta.Tagged, meta.StructTree)
+	}
+	if meta.Encrypted || meta.Repaired {
+		t.Errorf("ReadFile() Encrypted = %v, Repaired = %v, want false", meta.Encrypted, meta.Repaired)
+	}
+	if want := "Microsoft® Word for Microsoft 365"; meta.Producer != want || meta.Creator != want {
+		t.Errorf("ReadFile() Producer = %q, Creator = %q, want %q", meta.Producer, meta.Creator, want)
+	}
+	created := time.Date(2024, 4, 9, 5, 3, 28, 0, time.FixedZone("", 8*3600))
+	if !meta.CreationDate.Equal(created) {
+		t.Errorf("ReadFile() CreationDate = %v, want %v", meta.CreationDate, created)
+	}
+	if !bytes.Contains(meta.XMP, []byte("<x:xmpmeta")) {
+		t.Errorf("ReadFile() XMP = %q, want an XMP packet", meta.XMP)
+	}
+}
+
+func TestRead_InfoAndXMP(t *testing.T) {
+	meta := read(t, writePDF("1.4", false,
+		"<< /Type /Catalog /Pages 3 0 R /Metadata 4 0 R /Version /1.6 >>",
+		`<< /Title (Caf\351 \222 report) /Author <FEFF0041006E006E0061> /CreationDate (D:20230102030405-05'30') /ModDate (D:2023) >>`,
+		"<< /Type /Pages /Kids [] /Count 2 >>",
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(xmp), xmp),
+	))
+
+	if meta.Version != "1.6" {
+		t.Errorf("Read() Version = %v, want %v", meta.Version, "1.6")
+	}
+	if meta.PageCount != 2 {
+		t.Errorf("Read() PageCount = %v, want %v", meta.PageCount, 2)
+	}
+	if meta.Tagged || meta.StructTree {
+		t.Errorf("Read() Tagged = %v, StructTree = %v, want false", meta.Tagged, meta.StructTree)
+	}
+
+	fields := []struct {
+		name, got, want string
+	}{
+		{"Title", meta.Title, "Café ™ report"},
+		{"Author", meta.Author, "Anna"},
+		{"Subject", meta.Subject, "Quarterly report"},
+		{"Keywords", meta.Keywords, "quarterly, filings"},
+	}
+	for _, f := range fields {
+		if f.got != f.want {
+			t.Errorf("Read() %s = %q, want %q", f.name, f.got, f.want)
+		}
+	}
+
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.FixedZone("", -(5*3600+30*60)))
+	if !meta.CreationDate.Equal(created) {
+		t.Errorf("Read() CreationDate = %v, want %v", meta.CreationDate, created)
+	}
+	if modified := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC); !meta.ModDate.Equal(modified) {
+		t.Errorf("Read() ModDate = %v, want %v", meta.ModDate, modified)
+	}
+}
+
+func TestRead_XMPOnly(t *testing.T) {
+	meta := read(t, writePDF("2.0", false,
+		"<< /Type /Catalog /Pages 3 0 R /Metadata 4 0 R /MarkInfo << /Marked true >> >>",
+		"<< >>",
+		"<< /Type /Pages /Kids [] /Count 0 >>",
+		fmt.Sprintf("<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n%s\nendstream", len(xmp), xmp),
+	))
+
+	if meta.Title != "XMP title" || meta.Author != "Jane Doe" {
+		t.Errorf("Read() Title = %q, Author = %q, want values from XMP", meta.Title, meta.Author)
+	}
+	if modified := time.Date(2023, 6, 30, 12, 0, 0, 0, time.UTC); !meta.ModDate.Equal(modified) {
+		t.Errorf("Read() ModDate = %v, want %v", meta.ModDate, modified)
+	}
+	if !meta.Tagged {
+		t.Errorf("Read() Tagged = false, want true")
+	}
+}
+
+func TestRead_ObjectStreams(t *testing.T) {
+	meta := read(t, writePDF("1.5", true,
+		"<< /Type /Catalog /Pages 3 0 R /StructTreeRoot << /Type /StructTreeRoot >> >>",
+		"<< /Producer (Compressor) >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+	))
+
+	if meta.Repaired {
+		t.Errorf("Read() Repaired = true, want false")
+	}
+	if meta.PageCount != 1 || meta.Producer != "Compressor" || !meta.StructTree {
+		t.Errorf("Read() = %+v, want 1 page, producer and structure tree", meta)
+	}
+}
+
+func TestRead_Encrypted(t *testing.T) {
+	data := writePDF("1.4", false,
+		"<< /Type /Catalog /Pages 3 0 R >>",
+		"<< /Title (\x8a\x1f\x03) >>",
+		"<< /Type /Pages /Kids [] /Count 4 >>",
+	)
+	data = bytes.Replace(data, []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt << /Filter /Standard >>"), 1)
+	meta := read(t, data)
+
+	if !meta.Encrypted || meta.PageCount != 4 {
+		t.Errorf("Read() Encrypted = %v, PageCount = %v, want true, %v", meta.Encrypted, meta.PageCount, 4)
+	}
+	if meta.Title != "" {
+		t.Errorf("Read() Title = %q, want empty for an encrypted file", meta.Title)
+	}
+}
+
+func TestReadFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	html := filepath.Join(dir, "page.pdf")
+	if err := os.WriteFile(html, []byte("<html></html>"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := pdfmeta.ReadFile(html); !errors.Is(err, pdfmeta.ErrNotPDF) {
+		t.Errorf("ReadFile() error = %v, want ErrNotPDF", err)
+	}
+	if _, err := pdfmeta.ReadFile(filepath.Join(dir, "missing.pdf")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadFile() error = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,113 @@
+package pdfmeta
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/mhpenta/pypdftotext-client/internal/pdf"
+)
+
+// parseDate parses a PDF date string, D:YYYYMMDDHHmmSSOHH'mm'. Every part
+// after the year is optional. It returns the zero time if s is not a date.
+func parseDate(s string) time.Time {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "D:")
+
+	// Year, month, day, hour, minute and second.
+	fields := []int{0, 1, 1, 0, 0, 0}
+	widths := []int{4, 2, 2, 2, 2, 2}
+	for i, width := range widths {
+		if len(s) < width || !isDigits(s[:width]) {
+			if i == 0 {
+				return time.Time{}
+			}
+			break
+		}
+		fields[i], _ = strconv.Atoi(s[:width])
+		s = s[width:]
+	}
+
+	loc := time.UTC
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		offset := 0
+		zone := strings.Split(strings.TrimSuffix(s[1:], "'"), "'")
+		if len(zone[0]) == 2 && isDigits(zone[0]) {
+			hours, _ := strconv.Atoi(zone[0])
+			offset = hours * 3600
+			if len(zone) > 1 && len(zone[1]) == 2 && isDigits(zone[1]) {
+				minutes, _ := strconv.Atoi(zone[1])
+				offset += minutes * 60
+			}
+		}
+		if s[0] == '-' {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	return time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], 0, loc)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// textString decodes a PDF text string, which is UTF-16 or UTF-8 with a
+// byte order mark, or otherwise PDFDocEncoding.
+func textString(s pdf.String) string {
+	b := []byte(s)
+	switch {
+	case len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff:
+		return decodeUTF16(b[2:], true)
+	case len(b) >= 2 && b[0] == 0xff && b[1] == 0xfe:
+		return decodeUTF16(b[2:], false)
+	case len(b) >= 3 && b[0] == 0xef && b[1] == 0xbb && b[2] == 0xbf:
+		return strings.ToValidUTF8(string(b[3:]), "�")
+	}
+
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = pdfDocRune(c)
+	}
+	return string(runes)
+}
+
+func decodeUTF16(b []byte, bigEndian bool) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		} else {
+			units[i] = uint16(b[2*i+1])<<8 | uint16(b[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// pdfDocRune maps a byte in PDFDocEncoding to a rune. The encoding matches
+// Latin-1 except for a few control codes and the range 0x80 to 0xA0.
+func pdfDocRune(c byte) rune {
+	switch {
+	case c >= 0x18 && c <= 0x1f:
+		return pdfDocControls[c-0x18]
+	case c >= 0x80 && c <= 0xa0:
+		return pdfDocHigh[c-0x80]
+	case c == 0xad:
+		return '\uFFFD'
+	}
+	return rune(c)
+}
+
+var pdfDocControls = [...]rune{'˘', 'ˇ', 'ˆ', '˙', '˝', '˛', '˚', '˜'}
+
+var pdfDocHigh = [...]rune{
+	'•', '†', '‡', '…', '—', '–', 'ƒ', '⁄', '‹', '›', '−', '‰', '„', '“', '”', '‘',
+	'’', '‚', '™', 'ﬁ', 'ﬂ', 'Ł', 'Œ', 'Š', 'Ÿ', 'Ž', 'ı', 'ł', 'œ', 'š', 'ž', '\uFFFD',
+	'€',
+}
@@ -0,0 +1,111 @@
+package pdfmeta
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// Namespaces of the XMP properties read into Metadata.
+const (
+	nsRDF = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDC  = "http://purl.org/dc/elements/1.1/"
+	nsPDF = "http://ns.adobe.com/pdf/1.3/"
+	nsXMP = "http://ns.adobe.com/xap/1.0/"
+)
+
+// readXMP sets the fields of meta that the information dictionary left
+// empty from an XMP packet.
+func readXMP(data []byte, meta *Metadata) {
+	props := xmpProperties(data)
+	text := func(field *string, space, local string) {
+		if *field == "" {
+			*field = props[xml.Name{Space: space, Local: local}]
+		}
+	}
+	date := func(field *time.Time, space, local string) {
+		if field.IsZero() {
+			*field = parseXMPDate(props[xml.Name{Space: space, Local: local}])
+		}
+	}
+
+	text(&meta.Title, nsDC, "title")
+	text(&meta.Author, nsDC, "creator")
+	text(&meta.Subject, nsDC, "description")
+	text(&meta.Keywords, nsPDF, "Keywords")
+	text(&meta.Creator, nsXMP, "CreatorTool")
+	text(&meta.Producer, nsPDF, "Producer")
+	date(&meta.CreationDate, nsXMP, "CreateDate")
+	date(&meta.ModDate, nsXMP, "ModifyDate")
+}
+
+// xmpProperties returns the first value of each property in an XMP packet,
+// written either as an element or as an attribute of rdf:Description. The
+// value of an array property, such as dc:title, is its first item.
+func xmpProperties(data []byte) map[xml.Name]string {
+	description := xml.Name{Space: nsRDF, Local: "Description"}
+	props := map[xml.Name]string{}
+	set := func(name xml.Name, value string) {
+		value = strings.TrimSpace(value)
+		if _, ok := props[name]; !ok && value != "" {
+			props[name] = value
+		}
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	var stack []xml.Name
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return props
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name == description {
+				for _, attr := range t.Attr {
+					set(attr.Name, attr.Value)
+				}
+			}
+			stack = append(stack, t.Name)
+			text.Reset()
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			// The property is the child of the enclosing rdf:Description.
+			for i := len(stack) - 2; i >= 0; i-- {
+				if stack[i] == description {
+					set(stack[i+1], text.String())
+					break
+				}
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			text.Reset()
+		}
+	}
+}
+
+// parseXMPDate parses an XMP date, which is an ISO 8601 date and time of
+// varying precision. It returns the zero time if s is not a date.
+func parseXMPDate(s string) time.Time {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02",
+		"2006-01",
+		"2006",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,160 @@
+package pdfclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mhpenta/pypdftotext-client/internal/pdf"
+)
+
+// ErrEncryptedPDF is returned without uploading an encrypted PDF when
+// PreflightOptions.RejectEncrypted is set.
+var ErrEncryptedPDF = errors.New("encrypted PDF")
+
+// PreflightResult describes a PDF that passed preflight validation.
+type PreflightResult struct {
+	// Version is the PDF version in the file header, such as "1.7".
+	Version string
+	// PageCount is the number of pages. It is 0 for encrypted files whose
+	// page tree cannot be read.
+	PageCount int
+	Encrypted bool
+	// Repaired reports whether the cross-reference table was damaged, so
+	// the objects had to be located by scanning the file. The service
+	// repairs such files the same way.
+	Repaired bool
+}
+
+// Preflight checks locally that the size bytes of r are a PDF the service
+// can read: that the file starts with a %PDF- header and ends with %%EOF,
+// and that the trailer and the page tree it points to can be parsed. Files
+// that fail are reported with a *ClientError like the one the service would
+// return, matching ErrInvalidPDF.
+func Preflight(r io.ReaderAt, size int64) (*PreflightResult, error) {
+	doc, err := pdf.Open(r, size)
+	if err != nil {
+		return nil, preflightError(err)
+	}
+
+	result := &PreflightResult{
+		Version:   doc.Version(),
+		Encrypted: doc.Encrypted(),
+		Repaired:  doc.Repaired(),
+	}
+	count, err := doc.PageCount()
+	switch {
+	case err == nil:
+		result.PageCount = count
+	case result.Encrypted:
+		// The page tree may be in an encrypted object stream.
+	default:
+		return nil, preflightError(err)
+	}
+	return result, nil
+}
+
+// preflightError converts an error reading a PDF to the error the service
+// would return for it.
+func preflightError(err error) error {
+	var detail string
+	switch {
+	case errors.Is(err, pdf.ErrNotPDF):
+		detail = "Invalid PDF format: file does not appear to be a valid PDF"
+	case errors.Is(err, pdf.ErrTruncated):
+		detail = "PDF file is incomplete or truncated"
+	case errors.Is(err, pdf.ErrCorrupt):
+		detail = "PDF file appears to be corrupted: " + strings.TrimPrefix(err.Error(), pdf.ErrCorrupt.Error()+": ")
+	default:
+		return fmt.Errorf("error reading PDF: %w", err)
+	}
+
+	return &ClientError{
+		StatusCode: http.StatusBadRequest,
+		Message:    "preflight validation failed",
+		Detail:     detail,
+		Code:       CodeInvalidPDF,
+	}
+}
+
+// PreflightOptions configures WithPreflight.
+type PreflightOptions struct {
+	// RejectEncrypted fails encrypted PDFs with ErrEncryptedPDF instead of
+	// uploading them.
+	RejectEncrypted bool
+}
+
+// WithPreflight validates PDFs with Preflight before uploading them, so
+// files that are not PDFs, such as HTML error pages, or that were truncated
+// fail without a round trip to the service.
+func WithPreflight(options PreflightOptions) ClientOption {
+	return func(c *Client) {
+		c.preflight = &options
+	}
+}
+
+// preflightReader validates the rest of reader, leaving it at its current
+// offset.
+func (c *Client) preflightReader(reader io.Reader, fileName string) error {
+	r, size, err := readerAt(reader)
+	if err != nil || r == nil {
+		return err
+	}
+	if rs, ok := r.(*readSeekerAt); ok {
+		defer rs.r.Seek(rs.base, io.SeekStart)
+	}
+
+	result, err := Preflight(r, size)
+	if err != nil {
+		return err
+	}
+	if result.Encrypted && c.preflight.RejectEncrypted {
+		return fmt.Errorf("%w: %s", ErrEncryptedPDF, fileName)
+	}
+	return nil
+}
+
+// readerAt returns the rest of reader, from its current offset, as an
+// io.ReaderAt, along with its size. It returns a nil io.ReaderAt for readers
+// that are not seekable.
+func readerAt(reader io.Reader) (io.ReaderAt, int64, error) {
+	seeker, ok := reader.(io.ReadSeeker)
+	if !ok {
+		return nil, 0, nil
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, nil
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading file size: %w", err)
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("error rewinding file data: %w", err)
+	}
+
+	if at, ok := reader.(io.ReaderAt); ok {
+		return io.NewSectionReader(at, start, end-start), end - start, nil
+	}
+	return &readSeekerAt{r: seeker, base: start}, end - start, nil
+}
+
+// readSeekerAt implements io.ReaderAt by seeking a reader without ReadAt.
+type readSeekerAt struct {
+	r    io.ReadSeeker
+	base int64
+}
+
+func (r *readSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := r.r.Seek(r.base+off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r.r, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
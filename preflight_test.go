@@ -0,0 +1,128 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func readFixture(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("fixtures/example.pdf")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func TestPreflight(t *testing.T) {
+	data := readFixture(t)
+
+	result, err := pdfclient.Preflight(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Preflight() error = %v", err)
+	}
+	if result.Version != "1.7" {
+		t.Errorf("Preflight() Version = %v, want %v", result.Version, "1.7")
+	}
+	if result.PageCount != 15 {
+		t.Errorf("Preflight() PageCount = %v, want %v", result.PageCount, 15)
+	}
+	if result.Encrypted || result.Repaired {
+		t.Errorf("Preflight() Encrypted = %v, Repaired = %v, want false", result.Encrypted, result.Repaired)
+	}
+}
+
+func TestPreflight_Invalid(t *testing.T) {
+	data := readFixture(t)
+	tests := []struct {
+		name   string
+		data   []byte
+		detail string
+	}{
+		{"HTML", []byte("<html><body>502 Bad Gateway</body></html>"), pdfclienttest.DetailInvalidPDF},
+		{"truncated", data[:len(data)/2], pdfclienttest.DetailTruncatedPDF},
+		{"no startxref", []byte(testPDF), "PDF file appears to be corrupted: missing startxref"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pdfclient.Preflight(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if !errors.Is(err, pdfclient.ErrInvalidPDF) {
+				t.Fatalf("Preflight() error = %v, want ErrInvalidPDF", err)
+			}
+			var clientErr *pdfclient.ClientError
+			if !errors.As(err, &clientErr) || clientErr.Detail != tt.detail {
+				t.Errorf("Preflight() error detail = %q, want %q", clientErr.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestWithPreflight(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithPreflight(pdfclient.PreflightOptions{}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	_, err = client.ExtractTextFromBytes(ctx, []byte("<html>Not Found</html>"), "missing.pdf")
+	if !errors.Is(err, pdfclient.ErrInvalidPDF) {
+		t.Errorf("ExtractTextFromBytes() error = %v, want an invalid PDF error", err)
+	}
+	if n := countRequests(server, "/extract"); n != 0 {
+		t.Errorf("server received %d uploads, want %d", n, 0)
+	}
+
+	// A valid file is uploaded from the offset the reader was left at.
+	reader := bytes.NewReader(append([]byte("junk"), readFixture(t)...))
+	reader.Seek(4, io.SeekStart)
+	if _, err := client.ExtractTextFromReader(ctx, reader, "example.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromReader() error = %v", err)
+	}
+	request, _ := server.LastRequest()
+	if !bytes.HasPrefix(request.File, []byte("%PDF-")) {
+		t.Errorf("uploaded file starts with %q, want %%PDF-", request.File[:8])
+	}
+
+	// Readers that are not seekable are spooled and validated.
+	_, err = client.ExtractTextFromReader(ctx, io.MultiReader(bytes.NewReader([]byte("not a PDF"))), "stream.pdf")
+	if !errors.Is(err, pdfclient.ErrInvalidPDF) {
+		t.Errorf("ExtractTextFromReader() error = %v, want an invalid PDF error", err)
+	}
+	if n := countRequests(server, "/extract"); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+}
+
+func TestWithPreflight_RejectEncrypted(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	data := bytes.Replace(readFixture(t), []byte("/Root "), []byte("/Encrypt << /Filter /Standard >> /Root "), -1)
+	result, err := pdfclient.Preflight(bytes.NewReader(data), int64(len(data)))
+	if err != nil || !result.Encrypted {
+		t.Fatalf("Preflight() = %+v, %v, want an encrypted result", result, err)
+	}
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithPreflight(pdfclient.PreflightOptions{RejectEncrypted: true}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	_, err = client.ExtractTextFromBytes(context.Background(), data, "secret.pdf")
+	if !errors.Is(err, pdfclient.ErrEncryptedPDF) {
+		t.Errorf("ExtractTextFromBytes() error = %v, want ErrEncryptedPDF", err)
+	}
+	if n := countRequests(server, "/extract"); n != 0 {
+		t.Errorf("server received %d uploads, want %d", n, 0)
+	}
+}
@@ -0,0 +1,143 @@
+package pdfclient
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter paces API calls. Wait blocks until n tokens are available or ctx
+// is done, in which case it returns the context error. A Limiter may be
+// shared between clients to enforce a common quota.
+type Limiter interface {
+	Wait(ctx context.Context, n int) error
+}
+
+// TokenBucket is a Limiter refilled at a fixed rate up to a burst size.
+// Requests larger than the burst are admitted by borrowing against future
+// refills, so the average rate is kept without blocking forever.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full TokenBucket refilled at rate tokens per
+// second and holding at most burst tokens. A rate of zero or less does not
+// limit.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	burst = max(burst, 1)
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Wait(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if b.rate <= 0 || n <= 0 {
+		return nil
+	}
+
+	wait := b.reserve(float64(n))
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		b.release(float64(n))
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve takes n tokens and returns how long the caller must wait for the
+// bucket to pay them back.
+func (b *TokenBucket) reserve(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// release returns n tokens reserved by a cancelled wait.
+func (b *TokenBucket) release(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	b.tokens = min(b.burst, b.tokens+n)
+}
+
+func (b *TokenBucket) refill() {
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// WithRateLimit limits the client to requestsPerSecond requests, allowing
+// bursts of up to burst requests. Every attempt, including retries, counts
+// as a request.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return WithLimiter(NewTokenBucket(requestsPerSecond, burst))
+}
+
+// WithLimiter paces the client's requests with limiter, taking one token per
+// request. Use it to share a limiter between clients.
+func WithLimiter(limiter Limiter) ClientOption {
+	return func(c *Client) {
+		c.RequestLimiter = limiter
+	}
+}
+
+// WithUploadRateLimit limits the volume of request bodies to bytesPerSecond.
+func WithUploadRateLimit(bytesPerSecond int) ClientOption {
+	return WithUploadLimiter(NewTokenBucket(float64(bytesPerSecond), max(bytesPerSecond, uploadChunkSize)))
+}
+
+// WithUploadLimiter paces request bodies with limiter, taking one token per
+// byte sent.
+func WithUploadLimiter(limiter Limiter) ClientOption {
+	return func(c *Client) {
+		c.UploadLimiter = limiter
+	}
+}
+
+// uploadChunkSize is the largest read of a rate limited request body.
+const uploadChunkSize = 32 * 1024
+
+// limitedReader paces reads from r with limiter.
+type limitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter Limiter
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > uploadChunkSize {
+		p = p[:uploadChunkSize]
+	}
+	n, err := l.r.Read(p)
+	if n > 0 {
+		if waitErr := l.limiter.Wait(l.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
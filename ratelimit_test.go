@@ -0,0 +1,131 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestTokenBucket_Wait(t *testing.T) {
+	bucket := pdfclient.NewTokenBucket(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := bucket.Wait(context.Background(), 1); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	// Two tokens are available at once, the other two take 10ms each.
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("Wait() took %v, want at least %v", elapsed, 15*time.Millisecond)
+	}
+}
+
+func TestTokenBucket_WaitCancelled(t *testing.T) {
+	bucket := pdfclient.NewTokenBucket(1, 1)
+	if err := bucket.Wait(context.Background(), 1); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := bucket.Wait(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Wait() took %v, want it to return when the context is done", elapsed)
+	}
+}
+
+func TestTokenBucket_Unlimited(t *testing.T) {
+	bucket := pdfclient.NewTokenBucket(0, 1)
+	for i := 0; i < 100; i++ {
+		if err := bucket.Wait(context.Background(), 1000); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+}
+
+func TestRateLimit_SharedBetweenClients(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	limiter := pdfclient.NewTokenBucket(50, 1)
+	first, err := pdfclient.NewClient(server.URL, pdfclient.WithLimiter(limiter))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	second, err := pdfclient.NewClient(server.URL, pdfclient.WithLimiter(limiter))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	start := time.Now()
+	for _, client := range []*pdfclient.Client{first, second, first, second} {
+		if _, err := client.HealthCheck(context.Background()); err != nil {
+			t.Fatalf("HealthCheck() error = %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("4 requests at 50/s took %v, want at least %v", elapsed, 55*time.Millisecond)
+	}
+}
+
+func TestRateLimit_Cancelled(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithRateLimit(1, 1))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = client.HealthCheck(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HealthCheck() error = %v, want context.DeadlineExceeded", err)
+	}
+	if len(server.Requests()) != 1 {
+		t.Errorf("server received %d requests, want %d", len(server.Requests()), 1)
+	}
+}
+
+func TestUploadRateLimit(t *testing.T) {
+	server := pdfclienttest.NewServer()
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithUploadRateLimit(1<<20))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// The bucket starts with a second's worth of tokens, so the first
+	// upload is sent at once and the second waits for its size to refill.
+	pdf := []byte(testPDF + string(bytes.Repeat([]byte(" "), 1<<20)) + "\n%%EOF\n")
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := client.ExtractTextFromBytes(context.Background(), pdf, "large.pdf"); err != nil {
+			t.Fatalf("ExtractTextFromBytes() error = %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+		t.Errorf("2MB at 1MB/s took %v, want at least %v", elapsed, 800*time.Millisecond)
+	}
+}
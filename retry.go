@@ -0,0 +1,152 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 1 are treated as 1.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles on every
+	// subsequent retry up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay. A server supplied Retry-After
+	// is honoured even when it exceeds MaxDelay.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of the delay that is randomised to
+	// spread out retries from concurrent callers.
+	Jitter float64
+	// Retryable reports whether an attempt that failed with err should be
+	// retried. It defaults to DefaultRetryable.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns a policy of four attempts with exponential
+// backoff starting at 500ms and capped at 30s.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		Retryable:   DefaultRetryable,
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// DefaultRetryable returns true for transient API errors (429, 502, 503 and
+// 504), timeouts, refused or reset connections and responses cut short.
+// Other network errors, such as a host that does not resolve, are returned
+// straight away.
+func DefaultRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		switch clientErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// RetryError is returned by a client configured with a RetryPolicy when a
+// request did not succeed. Err is the error of the final attempt.
+type RetryError struct {
+	Attempts int
+	Errors   []error
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("request failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) shouldRetry(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return DefaultRetryable(err)
+}
+
+// delay returns how long to wait after the given (1-based) failed attempt.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	d := p.BaseDelay
+	if d > 0 {
+		d = time.Duration(float64(d) * math.Pow(2, float64(attempt-1)))
+	}
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d < 0) {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 && d > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		d -= time.Duration(rand.Float64() * jitter * float64(d))
+	}
+
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) && clientErr.RetryAfter > d {
+		d = clientErr.RetryAfter
+	}
+	return d
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an HTTP date. It returns zero if the header is absent or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,188 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+func TestRetryPolicy_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"detail":"Service unavailable"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","version":"1.0.0"}`))
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	health, err := client.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+
+	if health.Status != "ok" {
+		t.Errorf("HealthCheck() status = %v, want %v", health.Status, "ok")
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server calls = %v, want %v", got, 3)
+	}
+}
+
+func TestDefaultRetryable(t *testing.T) {
+	network := func(err error) error {
+		return &pdfclient.NetworkError{
+			Method: http.MethodPost,
+			URL:    "http://pdf.invalid/extract",
+			Err:    &url.Error{Op: "Post", URL: "http://pdf.invalid/extract", Err: err},
+		}
+	}
+	dial := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: err}}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"service unavailable", &pdfclient.ClientError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"bad request", &pdfclient.ClientError{StatusCode: http.StatusBadRequest}, false},
+		{"timeout", network(os.ErrDeadlineExceeded), true},
+		{"connection refused", network(dial(syscall.ECONNREFUSED)), true},
+		{"connection reset", network(&net.OpError{Op: "read", Net: "tcp", Err: &os.SyscallError{Syscall: "read", Err: syscall.ECONNRESET}}), true},
+		{"unexpected EOF", network(io.ErrUnexpectedEOF), true},
+		{"host not found", network(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "pdf.invalid", IsNotFound: true}}), false},
+		{"other dial error", network(dial(syscall.EACCES)), false},
+		{"EOF", network(io.EOF), false},
+		{"cancelled", network(context.Canceled), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pdfclient.DefaultRetryable(tt.err); got != tt.want {
+				t.Errorf("DefaultRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_ReportsAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"detail":"Too many requests"}`))
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte("fake PDF content"), "test.pdf")
+
+	var retryErr *pdfclient.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("ExtractTextFromBytes() error = %v, want *RetryError", err)
+	}
+
+	if retryErr.Attempts != 2 || len(retryErr.Errors) != 2 {
+		t.Errorf("RetryError attempts = %v (%d errors), want 2", retryErr.Attempts, len(retryErr.Errors))
+	}
+
+	var clientErr pdfclient.ClientError
+	if !errors.As(err, &clientErr) || clientErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("ExtractTextFromBytes() error = %v, want HTTP 429 ClientError", err)
+	}
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("server calls = %v, want %v", got, 2)
+	}
+}
+
+func TestRetryPolicy_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"detail":"Invalid PDF format"}`))
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithRetryPolicy(pdfclient.DefaultRetryPolicy()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ExtractTextFromBytes(context.Background(), []byte("not a pdf"), "test.pdf")
+	if err == nil {
+		t.Fatal("ExtractTextFromBytes() error = nil, want error")
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server calls = %v, want %v", got, 1)
+	}
+}
+
+func TestRetryPolicy_HonoursRetryAfterAndContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.HealthCheck(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("HealthCheck() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("HealthCheck() took %v, expected it to stop when the context ended", elapsed)
+	}
+
+	var retryErr *pdfclient.RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 1 {
+		t.Errorf("HealthCheck() error = %v, want RetryError after 1 attempt", err)
+	}
+}
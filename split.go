@@ -0,0 +1,213 @@
+package pdfclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/mhpenta/pypdftotext-client/internal/pdf"
+)
+
+// DefaultPagesPerPart is the number of pages in each part extracted by
+// ExtractTextLarge when SplitOptions.PagesPerPart is not set.
+const DefaultPagesPerPart = 50
+
+// SplitOptions configures ExtractTextLarge.
+type SplitOptions struct {
+	// PagesPerPart is the maximum number of pages in each part. It defaults
+	// to DefaultPagesPerPart.
+	PagesPerPart int
+	// Concurrency is the maximum number of parts extracted at once. It
+	// defaults to DefaultBatchConcurrency, or with WithAdaptiveConcurrency
+	// to the maximum adaptive limit, leaving the client to pace the parts.
+	Concurrency int
+	// CallOptions are applied to the extraction of every part.
+	CallOptions []CallOption
+}
+
+// ExtractTextLarge extracts text from a PDF too large to extract in one
+// request. The file is split locally into parts of at most PagesPerPart
+// pages, each a complete PDF carrying the resources its pages use, and the
+// parts are extracted concurrently. A part the service rejects as too large
+// or times out on is split in half and retried.
+//
+// The result is the same as from ExtractTextFromFile: Pages holds the pages
+// of every part numbered as in the original file, and PageCount, FileName
+// and FileSize describe the whole file. Files with no more pages than one
+// part, and encrypted files, which cannot be split, are extracted in a
+// single request. With WithPages, only the selected pages are split and
+// extracted. The first part that fails cancels the others.
+func (c *Client) ExtractTextLarge(ctx context.Context, filePath string, opts SplitOptions) (*TextExtractionResponse, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer func(file *os.File) {
+		err = file.Close()
+		if err != nil {
+			c.logger().Error("Failed to close file", "error", err)
+		}
+	}(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading file size: %w", err)
+	}
+	fileName := filepath.Base(filePath)
+
+	doc, err := pdf.Open(file, info.Size())
+	if err != nil {
+		return nil, preflightError(err)
+	}
+	var pages []pdf.Page
+	if !doc.Encrypted() {
+		pages, err = doc.Pages()
+		if err != nil {
+			return nil, preflightError(err)
+		}
+	}
+
+	perPart := opts.PagesPerPart
+	if perPart <= 0 {
+		perPart = DefaultPagesPerPart
+	}
+	if doc.Encrypted() || len(pages) <= perPart {
+		return c.ExtractTextFromReader(ctx, file, fileName, opts.CallOptions...)
+	}
+
+	// Only the pages selected with WithPages are extracted; the parts are
+	// sent without the range.
+	selected := make([]int, len(pages))
+	for i := range selected {
+		selected[i] = i
+	}
+	callOptions := opts.CallOptions
+	if options := newCallOptions(opts.CallOptions); options.pages != "" {
+		ranges, err := parsePageRanges(options.pages)
+		if err != nil {
+			return nil, err
+		}
+		selected = selectPages(ranges, len(pages))
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("%w: %s has %d pages", ErrNoPagesSelected, fileName, len(pages))
+		}
+		callOptions = append(slices.Clip(callOptions), WithPages(""))
+	}
+
+	var parts [][]int
+	for start := 0; start < len(selected); start += perPart {
+		parts = append(parts, selected[start:min(start+perPart, len(selected))])
+	}
+
+	s := &splitter{client: c, doc: doc, fileName: fileName, opts: callOptions}
+	results := make([]*TextExtractionResponse, len(parts))
+	err = c.runParallel(ctx, len(parts), opts.Concurrency, func(ctx context.Context, i int, fail func(error)) {
+		response, err := s.extract(ctx, parts[i])
+		if err != nil {
+			fail(err)
+			return
+		}
+		results[i] = response
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	response := &TextExtractionResponse{
+		Pages:     make([]PageData, 0, len(selected)),
+		PageCount: len(pages),
+		FileName:  fileName,
+		FileSize:  int(info.Size()),
+		CacheHit:  true,
+		Endpoint:  results[0].Endpoint,
+	}
+	for _, result := range results {
+		response.Pages = append(response.Pages, result.Pages...)
+		response.CacheHit = response.CacheHit && result.CacheHit
+	}
+	return response, nil
+}
+
+// splitter extracts page ranges of a document as separate PDFs.
+type splitter struct {
+	client   *Client
+	fileName string
+	opts     []CallOption
+
+	mu  sync.Mutex // guards doc, which is not safe for concurrent use
+	doc *pdf.Reader
+}
+
+// extract extracts the pages with the given zero-based indexes, returning
+// them numbered as in the whole document.
+func (s *splitter) extract(ctx context.Context, indexes []int) (*TextExtractionResponse, error) {
+	// Parts left after another fails are not worth writing out.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	first, last := indexes[0]+1, indexes[len(indexes)-1]+1
+	data, err := s.write(indexes)
+	if err != nil {
+		return nil, fmt.Errorf("error splitting pages %d-%d: %w", first, last, err)
+	}
+
+	response, err := s.client.ExtractTextFromReader(ctx, bytes.NewReader(data), s.fileName, s.opts...)
+	if err != nil {
+		if len(indexes) > 1 && ctx.Err() == nil && (errors.Is(err, ErrTooLarge) || errors.Is(err, ErrTimeout)) {
+			s.client.logger().Debug("pdfclient splitting part", "file", s.fileName,
+				"first_page", first, "last_page", last, "error", err)
+			return s.extractHalves(ctx, indexes)
+		}
+		return nil, fmt.Errorf("error extracting pages %d-%d: %w", first, last, err)
+	}
+
+	pages := make([]PageData, len(response.Pages))
+	for i, page := range response.Pages {
+		if page.Page >= 1 && page.Page <= len(indexes) {
+			page.Page = indexes[page.Page-1] + 1
+		}
+		pages[i] = page
+	}
+	return &TextExtractionResponse{
+		Pages:    pages,
+		CacheHit: response.CacheHit,
+		Endpoint: response.Endpoint,
+	}, nil
+}
+
+func (s *splitter) extractHalves(ctx context.Context, indexes []int) (*TextExtractionResponse, error) {
+	mid := len(indexes) / 2
+	first, err := s.extract(ctx, indexes[:mid])
+	if err != nil {
+		return nil, err
+	}
+	second, err := s.extract(ctx, indexes[mid:])
+	if err != nil {
+		return nil, err
+	}
+	return &TextExtractionResponse{
+		Pages:    append(first.Pages, second.Pages...),
+		CacheHit: first.CacheHit && second.CacheHit,
+		Endpoint: first.Endpoint,
+	}, nil
+}
+
+// write returns a PDF holding the pages with the given indexes.
+func (s *splitter) write(indexes []int) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b bytes.Buffer
+	if err := s.doc.WritePages(&b, indexes); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,260 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfmeta"
+)
+
+// writeLargePDF writes a PDF of n pages sharing a font to a temporary file.
+// The content of page i shows the text "page-i".
+func writeLargePDF(t *testing.T, n int) string {
+	t.Helper()
+	var b bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...any) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), fmt.Sprintf(format, args...))
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	kids := make([]string, n)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> >>",
+		strings.Join(kids, " "), n)
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	for i := 1; i <= n; i++ {
+		content := fmt.Sprintf("BT /F1 12 Tf (page-%d) Tj ET", i)
+		object("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", len(offsets)+2)
+		object("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	path := filepath.Join(t.TempDir(), "large.pdf")
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
+
+var pageMarker = regexp.MustCompile(`\(page-(\d+)\)`)
+
+// newSplitServer starts a server with the handler of newSplitHandler.
+func newSplitServer(t *testing.T, maxPages int, uploads *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(newSplitHandler(maxPages, uploads))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newSplitHandler returns a handler that extracts the "page-N" text of each
+// uploaded page, or "page i" for PDFs without it, and rejects uploads of
+// more than maxPages pages as too large.
+func newSplitHandler(maxPages int, uploads *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		uploads.Add(1)
+
+		var texts []string
+		for _, m := range pageMarker.FindAllSubmatch(content, -1) {
+			texts = append(texts, "page-"+string(m[1]))
+		}
+		if len(texts) == 0 {
+			meta, err := pdfmeta.Read(bytes.NewReader(content), int64(len(content)))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"detail":"Invalid PDF format: file does not appear to be a valid PDF"}`))
+				return
+			}
+			for i := 1; i <= meta.PageCount; i++ {
+				texts = append(texts, fmt.Sprintf("page %d", i))
+			}
+		}
+		if maxPages > 0 && len(texts) > maxPages {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = w.Write([]byte(`{"detail":"File too large"}`))
+			return
+		}
+
+		response := pdfclient.TextExtractionResponse{
+			PageCount: len(texts),
+			FileName:  header.Filename,
+			FileSize:  len(content),
+		}
+		for i, text := range texts {
+			response.Pages = append(response.Pages, pdfclient.PageData{Page: i + 1, Text: text})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
+
+func TestExtractTextLarge(t *testing.T) {
+	var uploads atomic.Int32
+	server := newSplitServer(t, 0, &uploads)
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	path := writeLargePDF(t, 23)
+	response, err := client.ExtractTextLarge(context.Background(), path, pdfclient.SplitOptions{
+		PagesPerPart: 5,
+		Concurrency:  3,
+	})
+	if err != nil {
+		t.Fatalf("ExtractTextLarge() error = %v", err)
+	}
+
+	if n := uploads.Load(); n != 5 {
+		t.Errorf("server received %d uploads, want %d", n, 5)
+	}
+	if response.PageCount != 23 || len(response.Pages) != 23 {
+		t.Fatalf("ExtractTextLarge() PageCount = %v, %d pages, want %v", response.PageCount, len(response.Pages), 23)
+	}
+	for i, page := range response.Pages {
+		if want := fmt.Sprintf("page-%d", i+1); page.Page != i+1 || page.Text != want {
+			t.Errorf("ExtractTextLarge() Pages[%d] = %+v, want page %d %q", i, page, i+1, want)
+		}
+	}
+	info, _ := os.Stat(path)
+	if response.FileName != "large.pdf" || response.FileSize != int(info.Size()) {
+		t.Errorf("ExtractTextLarge() FileName = %v, FileSize = %v, want %v, %v",
+			response.FileName, response.FileSize, "large.pdf", info.Size())
+	}
+}
+
+func TestExtractTextLarge_SplitsTooLargeParts(t *testing.T) {
+	var uploads atomic.Int32
+	server := newSplitServer(t, 2, &uploads)
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	response, err := client.ExtractTextLarge(context.Background(), writeLargePDF(t, 10), pdfclient.SplitOptions{PagesPerPart: 5})
+	if err != nil {
+		t.Fatalf("ExtractTextLarge() error = %v", err)
+	}
+	for i, page := range response.Pages {
+		if page.Page != i+1 || page.Text != fmt.Sprintf("page-%d", i+1) {
+			t.Errorf("ExtractTextLarge() Pages[%d] = %+v, want page %d", i, page, i+1)
+		}
+	}
+	if len(response.Pages) != 10 {
+		t.Errorf("ExtractTextLarge() returned %d pages, want %d", len(response.Pages), 10)
+	}
+	// Each part of 5 is rejected, then split into parts of 2 and 3, and
+	// the parts of 3 again.
+	if n := uploads.Load(); n != 10 {
+		t.Errorf("server received %d uploads, want %d", n, 10)
+	}
+}
+
+func TestExtractTextLarge_SinglePart(t *testing.T) {
+	var uploads atomic.Int32
+	server := newSplitServer(t, 0, &uploads)
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	path := writeLargePDF(t, 3)
+	response, err := client.ExtractTextLarge(context.Background(), path, pdfclient.SplitOptions{})
+	if err != nil {
+		t.Fatalf("ExtractTextLarge() error = %v", err)
+	}
+	info, _ := os.Stat(path)
+	if uploads.Load() != 1 || response.PageCount != 3 || response.FileSize != int(info.Size()) {
+		t.Errorf("ExtractTextLarge() = %d uploads, %+v, want the whole file in one upload", uploads.Load(), response)
+	}
+}
+
+func TestExtractTextLarge_Fixture(t *testing.T) {
+	var uploads atomic.Int32
+	server := newSplitServer(t, 0, &uploads)
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	response, err := client.ExtractTextLarge(context.Background(), "fixtures/example.pdf", pdfclient.SplitOptions{PagesPerPart: 4})
+	if err != nil {
+		t.Fatalf("ExtractTextLarge() error = %v", err)
+	}
+	if n := uploads.Load(); n != 4 {
+		t.Errorf("server received %d uploads, want %d", n, 4)
+	}
+	if response.PageCount != 15 || len(response.Pages) != 15 {
+		t.Fatalf("ExtractTextLarge() PageCount = %v, %d pages, want %v", response.PageCount, len(response.Pages), 15)
+	}
+	for i, page := range response.Pages {
+		if want := fmt.Sprintf("page %d", i%4+1); page.Page != i+1 || page.Text != want {
+			t.Errorf("ExtractTextLarge() Pages[%d] = %+v, want page %d %q", i, page, i+1, want)
+		}
+	}
+}
+
+func TestExtractTextLarge_PartError(t *testing.T) {
+	var uploads atomic.Int32
+	handler := newSplitHandler(0, &uploads)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Uploads abandoned when the failing part cancels the others may
+		// arrive incomplete.
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		content, err := io.ReadAll(file)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if bytes.Contains(content, []byte("(page-7)")) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"detail":"PDF file appears to be corrupted: bad font"}`))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.ExtractTextLarge(context.Background(), writeLargePDF(t, 12), pdfclient.SplitOptions{PagesPerPart: 5})
+	if !errors.Is(err, pdfclient.ErrInvalidPDF) || !strings.Contains(err.Error(), "pages 6-10") {
+		t.Errorf("ExtractTextLarge() error = %v, want an invalid PDF error for pages 6-10", err)
+	}
+}
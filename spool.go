@@ -0,0 +1,219 @@
+package pdfclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// DefaultSpoolMemoryLimit is the number of bytes of a reader that is not
+// seekable held in memory before the rest is spooled to a temporary file.
+const DefaultSpoolMemoryLimit = 8 << 20
+
+// SpoolOptions configures WithSpooling.
+type SpoolOptions struct {
+	// MemoryLimit is the number of bytes held in memory before spooling to
+	// a temporary file. It defaults to DefaultSpoolMemoryLimit.
+	MemoryLimit int64
+	// Dir is the directory temporary files are created in. It defaults to
+	// os.TempDir.
+	Dir string
+}
+
+// WithSpooling configures how ExtractTextFromReader copies readers that are
+// not seekable, such as network streams and pipes, when the call may need to
+// read them more than once. The copy is kept in memory up to MemoryLimit
+// bytes and in a temporary file beyond that, and is discarded when the call
+// returns. When only a retry may read the input again, the copy is made as
+// the first attempt uploads it. Spooling happens regardless of this option,
+// which only changes its limits.
+func WithSpooling(options SpoolOptions) ClientOption {
+	return func(c *Client) {
+		c.spoolOptions = options
+	}
+}
+
+// needsContent reports whether a call with options reads its whole input
+// before uploading it: for the cache, deduplication, preflight validation or
+// page selection.
+func (c *Client) needsContent(options callOptions) bool {
+	return c.cache != nil || c.flights != nil || c.preflight != nil || options.pages != ""
+}
+
+// seekable reports whether reader can be rewound to its current offset.
+func seekable(reader io.Reader) bool {
+	seeker, ok := reader.(io.ReadSeeker)
+	if !ok {
+		return false
+	}
+	_, err := seeker.Seek(0, io.SeekCurrent)
+	return err == nil
+}
+
+// spool copies the rest of reader so it can be read more than once. The
+// returned function releases the copy and must be called when it is no
+// longer needed.
+func (c *Client) spool(reader io.Reader) (io.ReadSeeker, func(), error) {
+	limit := c.spoolLimit()
+
+	var buf bytes.Buffer
+	_, err := io.CopyN(&buf, reader, limit+1)
+	if errors.Is(err, io.EOF) {
+		return bytes.NewReader(buf.Bytes()), func() {}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading file data: %w", err)
+	}
+
+	file, err := os.CreateTemp(c.spoolOptions.Dir, "pdfclient-spool-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating spool file: %w", err)
+	}
+	release := func() { c.removeSpoolFile(file) }
+
+	if _, err := io.Copy(file, io.MultiReader(&buf, reader)); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("error spooling file data: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		release()
+		return nil, nil, fmt.Errorf("error rewinding spool file: %w", err)
+	}
+	return file, release, nil
+}
+
+func (c *Client) spoolLimit() int64 {
+	if c.spoolOptions.MemoryLimit <= 0 {
+		return DefaultSpoolMemoryLimit
+	}
+	return c.spoolOptions.MemoryLimit
+}
+
+func (c *Client) removeSpoolFile(file *os.File) {
+	if err := file.Close(); err != nil {
+		c.logger().Error("Failed to close spool file", "error", err)
+	}
+	if err := os.Remove(file.Name()); err != nil {
+		c.logger().Error("Failed to remove spool file", "file", file.Name(), "error", err)
+	}
+}
+
+// teeSpool reads a reader that is not seekable, copying what it reads so
+// that a retry can replay it. The source is read only as the upload needs
+// it, so the first attempt starts without waiting for the end of the stream,
+// and a retry continues from the source where an earlier attempt stopped.
+type teeSpool struct {
+	client *Client
+	first  spoolReader
+
+	mu     sync.Mutex // guards the fields below and reads of source
+	source io.Reader
+	err    error // error that ended source, such as io.EOF
+	mem    []byte
+	file   *os.File // holds the copy once it exceeds the memory limit
+	size   int64    // bytes copied from source
+}
+
+// newTeeSpool returns a spool of reader. Its release method must be called
+// when the copy is no longer needed.
+func (c *Client) newTeeSpool(reader io.Reader) *teeSpool {
+	s := &teeSpool{client: c, source: reader}
+	s.first.spool = s
+	return s
+}
+
+// Read reads the input once, as the first attempt does.
+func (s *teeSpool) Read(p []byte) (int, error) {
+	return s.first.Read(p)
+}
+
+// replay returns a reader of the input from the start, independent of any
+// earlier reader, which may still be in use by an abandoned attempt.
+func (s *teeSpool) replay() io.Reader {
+	return &spoolReader{spool: s}
+}
+
+func (s *teeSpool) readAt(p []byte, offset int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offset < s.size {
+		p = p[:min(int64(len(p)), s.size-offset)]
+		if s.file == nil {
+			return copy(p, s.mem[offset:]), nil
+		}
+		n, err := s.file.ReadAt(p, offset)
+		if err != nil && n < len(p) {
+			return n, fmt.Errorf("error reading spool file: %w", err)
+		}
+		return n, nil
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	n, err := s.source.Read(p)
+	if n > 0 {
+		if storeErr := s.store(p[:n]); storeErr != nil {
+			s.err = storeErr
+			return 0, storeErr
+		}
+	}
+	if err != nil {
+		s.err = err
+	}
+	return n, err
+}
+
+// store appends b to the copy, moving it to a temporary file once it
+// exceeds the memory limit.
+func (s *teeSpool) store(b []byte) error {
+	if s.file == nil && s.size+int64(len(b)) > s.client.spoolLimit() {
+		file, err := os.CreateTemp(s.client.spoolOptions.Dir, "pdfclient-spool-*")
+		if err != nil {
+			return fmt.Errorf("error creating spool file: %w", err)
+		}
+		s.file = file
+		if _, err := file.Write(s.mem); err != nil {
+			return fmt.Errorf("error spooling file data: %w", err)
+		}
+		s.mem = nil
+	}
+
+	if s.file != nil {
+		if _, err := s.file.Write(b); err != nil {
+			return fmt.Errorf("error spooling file data: %w", err)
+		}
+	} else {
+		s.mem = append(s.mem, b...)
+	}
+	s.size += int64(len(b))
+	return nil
+}
+
+// release discards the copy.
+func (s *teeSpool) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		s.client.removeSpoolFile(s.file)
+		s.file = nil
+	}
+	s.err = errors.New("spool released")
+	s.mem, s.size = nil, 0
+}
+
+// spoolReader reads a teeSpool from its own offset.
+type spoolReader struct {
+	spool  *teeSpool
+	offset int64
+}
+
+func (r *spoolReader) Read(p []byte) (int, error) {
+	n, err := r.spool.readAt(p, r.offset)
+	r.offset += int64(n)
+	return n, err
+}
@@ -0,0 +1,219 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestSpooling_RetriesNonSeekableReader(t *testing.T) {
+	recorder := &uploadRecorder{failFirst: true}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	content := []byte("fake PDF content")
+	if _, err := client.ExtractTextFromReader(context.Background(), io.MultiReader(bytes.NewReader(content)), "test.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromReader() error = %v", err)
+	}
+
+	if got := recorder.calls.Load(); got != 2 {
+		t.Errorf("server calls = %v, want %v", got, 2)
+	}
+	if !bytes.Equal(recorder.fileContent, content) {
+		t.Errorf("uploaded content = %q, want %q", recorder.fileContent, content)
+	}
+}
+
+func TestSpooling_RetryStreamsFirstAttempt(t *testing.T) {
+	tests := []struct {
+		name string
+		// readBody makes the failing first attempt read the whole upload
+		// before responding; otherwise it responds before the stream ends.
+		readBody bool
+	}{
+		{"first attempt read", true},
+		{"first attempt stopped early", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrived := make(chan struct{})
+			recorder := &uploadRecorder{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if recorder.calls.Load() == 0 {
+					recorder.calls.Add(1)
+					close(arrived)
+					if tt.readBody {
+						_, _ = io.Copy(io.Discard, r.Body)
+					}
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				recorder.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			client, err := pdfclient.NewClient(server.URL,
+				pdfclient.WithSpooling(pdfclient.SpoolOptions{MemoryLimit: 4, Dir: t.TempDir()}),
+				pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			// The first attempt starts before the stream ends, and the retry
+			// replays what it read followed by the rest of the stream.
+			pr, pw := io.Pipe()
+			go func() {
+				_, _ = pw.Write([]byte("fake PDF "))
+				select {
+				case <-arrived:
+				case <-time.After(5 * time.Second):
+					pw.CloseWithError(errors.New("no request before the end of the stream"))
+					return
+				}
+				_, _ = pw.Write([]byte("content"))
+				pw.Close()
+			}()
+
+			if _, err := client.ExtractTextFromReader(context.Background(), pr, "test.pdf"); err != nil {
+				t.Fatalf("ExtractTextFromReader() error = %v", err)
+			}
+			if got := recorder.calls.Load(); got != 2 {
+				t.Errorf("server calls = %v, want %v", got, 2)
+			}
+			if string(recorder.fileContent) != "fake PDF content" {
+				t.Errorf("retried upload = %q, want %q", recorder.fileContent, "fake PDF content")
+			}
+		})
+	}
+}
+
+func TestSpooling_TemporaryFile(t *testing.T) {
+	dir := t.TempDir()
+	var spooled []os.DirEntry
+	recorder := &uploadRecorder{failFirst: true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		spooled, _ = os.ReadDir(dir)
+		recorder.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithSpooling(pdfclient.SpoolOptions{MemoryLimit: 4, Dir: dir}),
+		pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	content := []byte("fake PDF content")
+	if _, err := client.ExtractTextFromReader(context.Background(), io.MultiReader(bytes.NewReader(content)), "test.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromReader() error = %v", err)
+	}
+
+	if len(spooled) != 1 {
+		t.Errorf("spool directory held %d files during the upload, want %d", len(spooled), 1)
+	}
+	if !bytes.Equal(recorder.fileContent, content) {
+		t.Errorf("uploaded content = %q, want %q", recorder.fileContent, content)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("spool directory holds %d files after the call, want %d", len(entries), 0)
+	}
+}
+
+func TestSpooling_Cleanup(t *testing.T) {
+	server := pdfclienttest.NewServer(
+		pdfclienttest.WithFileError("bad.pdf", http.StatusBadRequest, pdfclienttest.DetailInvalidPDF),
+	)
+	defer server.Close()
+
+	dir := t.TempDir()
+	client, err := pdfclient.NewClient(server.URL,
+		pdfclient.WithSpooling(pdfclient.SpoolOptions{MemoryLimit: 4, Dir: dir}),
+		pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		reader io.Reader
+		file   string
+	}{
+		{"service error", io.MultiReader(strings.NewReader(testPDF)), "bad.pdf"},
+		{"read error", io.MultiReader(strings.NewReader("%PDF-1.4 partial"), iotest.ErrReader(errors.New("connection reset"))), "stream.pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.ExtractTextFromReader(context.Background(), tt.reader, tt.file); err == nil {
+				t.Errorf("ExtractTextFromReader() error = nil, want an error")
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("spool directory holds %d files after the call, want %d", len(entries), 0)
+			}
+		})
+	}
+
+	if n := countRequests(server, "/extract"); n != 1 {
+		t.Errorf("server received %d uploads, want %d", n, 1)
+	}
+}
+
+func TestSpooling_StreamsWithoutReplay(t *testing.T) {
+	arrived := make(chan struct{})
+	recorder := &uploadRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(arrived)
+		recorder.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	// Without retries or anything else reading the input twice, the upload
+	// starts before the stream ends.
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte("fake PDF "))
+		select {
+		case <-arrived:
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = pw.Write([]byte("content"))
+		pw.Close()
+	}()
+
+	if _, err := client.ExtractTextFromReader(context.Background(), pr, "test.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromReader() error = %v", err)
+	}
+	select {
+	case <-arrived:
+	default:
+		t.Fatal("server received no request")
+	}
+	if recorder.contentLength != -1 || string(recorder.fileContent) != "fake PDF content" {
+		t.Errorf("upload = %d bytes %q, want a chunked upload of %q",
+			recorder.contentLength, recorder.fileContent, "fake PDF content")
+	}
+}
@@ -0,0 +1,216 @@
+package pdfclient
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencyWindow is the number of most recent call durations kept per
+// operation for computing percentiles.
+const latencyWindow = 1024
+
+// Stats is a snapshot of the calls made by a Client.
+type Stats struct {
+	// Total aggregates all operations.
+	Total OperationStats
+	// Operations holds the stats of each operation, keyed by OpHealthCheck,
+	// OpExtract, OpExtractGCS and OpCapabilities.
+	Operations map[string]OperationStats
+
+	// ConcurrencyLimit is the current limit set by WithAdaptiveConcurrency,
+	// and LimitDecisions its most recent changes, oldest first. Both are
+	// zero without an adaptive limit.
+	ConcurrencyLimit int
+	LimitDecisions   []LimitDecision
+}
+
+// OperationStats counts the calls of one operation.
+type OperationStats struct {
+	Requests      int64
+	InFlight      int64
+	Succeeded     int64
+	Failed        int64
+	Retries       int64
+	BytesSent     int64
+	BytesReceived int64
+	Pages         int64
+	// CacheHits counts the successful calls served by WithCache. They are
+	// not included in Latency and QueueTime, which describe calls sent to
+	// the service.
+	CacheHits int64
+	Latency   LatencySummary
+	// QueueTime summarises the time calls waited for WithMaxConcurrent or
+	// WithMaxInFlightBytes.
+	QueueTime LatencySummary
+}
+
+// LatencySummary summarises the durations of the most recent completed calls.
+type LatencySummary struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// StatsRecorder is an Observer that keeps counters and latency percentiles
+// in memory. Every Client has one, reported by Client.Stats; a StatsRecorder
+// can also be shared between clients with WithObserver.
+type StatsRecorder struct {
+	mu         sync.Mutex
+	operations map[string]*operationRecorder
+}
+
+type operationRecorder struct {
+	stats     OperationStats
+	latencies window
+	queued    window
+}
+
+// window keeps the latencyWindow most recent durations.
+type window struct {
+	samples []time.Duration
+	next    int
+}
+
+func (w *window) add(d time.Duration) {
+	if len(w.samples) < latencyWindow {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencyWindow
+}
+
+// NewStatsRecorder returns an empty StatsRecorder.
+func NewStatsRecorder() *StatsRecorder {
+	return &StatsRecorder{operations: make(map[string]*operationRecorder)}
+}
+
+// Stats returns the stats of the calls made by the client.
+func (c *Client) Stats() Stats {
+	stats := Stats{Operations: map[string]OperationStats{}}
+	if c.stats != nil {
+		stats = c.stats.Stats()
+	}
+	if c.adaptive != nil {
+		stats.ConcurrencyLimit, stats.LimitDecisions = c.adaptive.state()
+	}
+	return stats
+}
+
+// Stats returns a snapshot of the recorded stats.
+func (s *StatsRecorder) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := Stats{Operations: make(map[string]OperationStats, len(s.operations))}
+	var latencies, queued []time.Duration
+	for name, op := range s.operations {
+		stats := op.stats
+		stats.Latency = summarize(op.latencies.samples)
+		stats.QueueTime = summarize(op.queued.samples)
+		snapshot.Operations[name] = stats
+
+		snapshot.Total.Requests += stats.Requests
+		snapshot.Total.InFlight += stats.InFlight
+		snapshot.Total.Succeeded += stats.Succeeded
+		snapshot.Total.Failed += stats.Failed
+		snapshot.Total.Retries += stats.Retries
+		snapshot.Total.BytesSent += stats.BytesSent
+		snapshot.Total.BytesReceived += stats.BytesReceived
+		snapshot.Total.Pages += stats.Pages
+		latencies = append(latencies, op.latencies.samples...)
+		queued = append(queued, op.queued.samples...)
+	}
+	snapshot.Total.Latency = summarize(latencies)
+	snapshot.Total.QueueTime = summarize(queued)
+
+	return snapshot
+}
+
+func summarize(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	percentile := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+
+	return LatencySummary{
+		Count: len(sorted),
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+func (s *StatsRecorder) operation(name string) *operationRecorder {
+	op, ok := s.operations[name]
+	if !ok {
+		op = &operationRecorder{}
+		s.operations[name] = op
+	}
+	return op
+}
+
+func (s *StatsRecorder) OnRequestStart(ctx context.Context, info RequestInfo) context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := s.operation(info.Operation)
+	op.stats.Requests++
+	op.stats.InFlight++
+	return ctx
+}
+
+func (s *StatsRecorder) OnRetry(_ context.Context, info RetryInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.operation(info.Operation).stats.Retries++
+}
+
+func (s *StatsRecorder) OnResponse(_ context.Context, info ResponseInfo) {
+	s.finish(info, true)
+}
+
+func (s *StatsRecorder) OnError(_ context.Context, info ResponseInfo, _ error) {
+	s.finish(info, false)
+}
+
+func (s *StatsRecorder) finish(info ResponseInfo, succeeded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := s.operation(info.Operation)
+	op.stats.InFlight--
+	if succeeded {
+		op.stats.Succeeded++
+	} else {
+		op.stats.Failed++
+	}
+	op.stats.BytesSent += info.BytesSent
+	op.stats.BytesReceived += info.BytesReceived
+	op.stats.Pages += int64(info.PageCount)
+	if info.CacheHit {
+		op.stats.CacheHits++
+		return
+	}
+	op.latencies.add(info.Duration)
+	op.queued.add(info.QueueTime)
+}
@@ -0,0 +1,126 @@
+package pdfclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+)
+
+// uploadBody streams a multipart/form-data upload of reader without
+// buffering the file contents. Only the multipart header and trailer are held
+// in memory; the file itself is read by the HTTP transport as the request is
+// sent.
+type uploadBody struct {
+	reader      io.Reader
+	size        int64 // size of the remaining input, or -1 if unknown
+	start       int64 // offset to rewind to before a retry
+	replay      func() io.Reader
+	header      []byte
+	trailer     []byte
+	contentType string
+	sent        bool
+}
+
+// newUploadBody prepares an upload of reader as fileName, preceded by the
+// given form fields.
+func newUploadBody(reader io.Reader, fileName string, fields [][2]string) (*uploadBody, error) {
+	envelope := &bytes.Buffer{}
+	writer := multipart.NewWriter(envelope)
+
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return nil, fmt.Errorf("error writing form field: %w", err)
+		}
+	}
+
+	if _, err := writer.CreateFormFile("file", fileName); err != nil {
+		return nil, fmt.Errorf("error creating form file: %w", err)
+	}
+	headerLen := envelope.Len()
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error closing multipart writer: %w", err)
+	}
+
+	u := &uploadBody{
+		reader:      reader,
+		size:        -1,
+		header:      envelope.Bytes()[:headerLen],
+		trailer:     envelope.Bytes()[headerLen:],
+		contentType: writer.FormDataContentType(),
+	}
+
+	if seeker, ok := reader.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			u.start = start
+		} else {
+			u.start = -1
+		}
+	} else {
+		u.start = -1
+	}
+
+	if spool, ok := reader.(*teeSpool); ok {
+		u.replay = spool.replay
+	}
+	u.size = inputSize(reader, u.start)
+
+	return u, nil
+}
+
+// inputSize returns the number of bytes left in reader, or -1 if it cannot be
+// determined without consuming it.
+func inputSize(reader io.Reader, offset int64) int64 {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		// *bytes.Reader, *bytes.Buffer and *strings.Reader
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() || offset < 0 {
+			return -1
+		}
+		return info.Size() - offset
+	}
+	return -1
+}
+
+// replayable reports whether the body can be sent more than once.
+func (u *uploadBody) replayable() bool {
+	return u.start >= 0 || u.replay != nil
+}
+
+// open returns a reader producing the complete multipart body together with
+// its length, or -1 if the length is unknown and the body must be sent with
+// chunked transfer encoding.
+func (u *uploadBody) open() (io.Reader, int64, error) {
+	reader := u.reader
+	if u.sent {
+		switch {
+		case u.replay != nil:
+			reader = u.replay()
+		case !u.replayable():
+			return nil, 0, fmt.Errorf("upload of a non-seekable reader cannot be repeated")
+		default:
+			if _, err := u.reader.(io.Seeker).Seek(u.start, io.SeekStart); err != nil {
+				return nil, 0, fmt.Errorf("error rewinding file data: %w", err)
+			}
+		}
+	}
+	u.sent = true
+
+	body := io.MultiReader(bytes.NewReader(u.header), reader, bytes.NewReader(u.trailer))
+	return body, u.length(), nil
+}
+
+// length returns the length of the complete multipart body, or -1 if it is
+// unknown.
+func (u *uploadBody) length() int64 {
+	if u.size < 0 {
+		return -1
+	}
+	return int64(len(u.header)) + u.size + int64(len(u.trailer))
+}
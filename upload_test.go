@@ -0,0 +1,133 @@
+package pdfclient_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+)
+
+// uploadRecorder is a handler that records the framing and file contents of
+// multipart uploads.
+type uploadRecorder struct {
+	contentLength    int64
+	transferEncoding []string
+	fileContent      []byte
+	calls            atomic.Int32
+	failFirst        bool
+}
+
+func (u *uploadRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if u.calls.Add(1) == 1 && u.failFirst {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	u.contentLength = r.ContentLength
+	u.transferEncoding = r.TransferEncoding
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	u.fileContent, _ = io.ReadAll(file)
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"pages":[{"page":1,"text":"ok"}],"page_count":1,"file_name":"test.pdf","file_size":16}`))
+}
+
+func TestExtractTextFromBytes_SetsContentLength(t *testing.T) {
+	recorder := &uploadRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	content := []byte("fake PDF content")
+	if _, err := client.ExtractTextFromBytes(context.Background(), content, "test.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromBytes() error = %v", err)
+	}
+
+	if recorder.contentLength <= int64(len(content)) {
+		t.Errorf("Content-Length = %v, want a known length larger than %v", recorder.contentLength, len(content))
+	}
+
+	if !bytes.Equal(recorder.fileContent, content) {
+		t.Errorf("uploaded content = %q, want %q", recorder.fileContent, content)
+	}
+}
+
+func TestExtractTextFromReader_UnknownLengthIsChunked(t *testing.T) {
+	recorder := &uploadRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	content := []byte("fake PDF content")
+	// io.MultiReader hides the length of the underlying reader.
+	reader := io.MultiReader(bytes.NewReader(content))
+	if _, err := client.ExtractTextFromReader(context.Background(), reader, "test.pdf"); err != nil {
+		t.Fatalf("ExtractTextFromReader() error = %v", err)
+	}
+
+	if recorder.contentLength != -1 {
+		t.Errorf("Content-Length = %v, want -1", recorder.contentLength)
+	}
+
+	if len(recorder.transferEncoding) != 1 || recorder.transferEncoding[0] != "chunked" {
+		t.Errorf("Transfer-Encoding = %v, want [chunked]", recorder.transferEncoding)
+	}
+
+	if !bytes.Equal(recorder.fileContent, content) {
+		t.Errorf("uploaded content = %q, want %q", recorder.fileContent, content)
+	}
+}
+
+func TestExtractTextFromFile_RetryRewindsFile(t *testing.T) {
+	recorder := &uploadRecorder{failFirst: true}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithRetryPolicy(pdfclient.RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	content := []byte("fake PDF content")
+	tempFile := filepath.Join(t.TempDir(), "test.pdf")
+	if err := os.WriteFile(tempFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := client.ExtractTextFromFile(context.Background(), tempFile); err != nil {
+		t.Fatalf("ExtractTextFromFile() error = %v", err)
+	}
+
+	if got := recorder.calls.Load(); got != 2 {
+		t.Errorf("server calls = %v, want %v", got, 2)
+	}
+
+	if !bytes.Equal(recorder.fileContent, content) {
+		t.Errorf("uploaded content = %q, want %q", recorder.fileContent, content)
+	}
+}
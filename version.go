@@ -0,0 +1,176 @@
+package pdfclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrIncompatibleServer is returned without sending a request when the
+// service's version does not satisfy the constraint given to
+// WithServerVersionConstraint.
+var ErrIncompatibleServer = errors.New("incompatible server version")
+
+// WithServerVersionConstraint requires the service to report a version
+// satisfying constraint, such as ">=1.2 <2". The constraint is a list of
+// comparisons, separated by spaces or commas, that must all hold; the
+// operators are =, !=, <, <=, > and >=, and a version without an operator
+// must match exactly. Missing version components count as zero and
+// pre-release suffixes are ignored.
+//
+// The version is discovered with a health check before the first
+// extraction and cached. Extractions fail with ErrIncompatibleServer if it
+// does not satisfy the constraint; call CheckServerVersion at startup to
+// find out before then. NewClient returns an error if the constraint cannot
+// be parsed.
+func WithServerVersionConstraint(constraint string) ClientOption {
+	return func(c *Client) {
+		c.versionConstraint = constraint
+	}
+}
+
+// versionConstraint is a parsed version constraint.
+type versionConstraint struct {
+	text        string
+	comparisons []versionComparison
+}
+
+type versionComparison struct {
+	op      string
+	version []int
+}
+
+func parseVersionConstraint(text string) (*versionConstraint, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("invalid server version constraint %q: no comparisons", text)
+	}
+
+	constraint := &versionConstraint{text: text}
+	for i := 0; i < len(fields); i++ {
+		op, rest := "=", fields[i]
+		for _, candidate := range []string{">=", "<=", "!=", "==", ">", "<", "="} {
+			if strings.HasPrefix(rest, candidate) {
+				op, rest = strings.Replace(candidate, "==", "=", 1), rest[len(candidate):]
+				break
+			}
+		}
+		// Allow a space between the operator and the version.
+		if rest == "" && i+1 < len(fields) {
+			i++
+			rest = fields[i]
+		}
+
+		version, err := parseVersion(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid server version constraint %q: %w", text, err)
+		}
+		constraint.comparisons = append(constraint.comparisons, versionComparison{op: op, version: version})
+	}
+	return constraint, nil
+}
+
+// parseVersion parses a dotted version such as "1.2.3" or "v1.2.0-rc1".
+func parseVersion(text string) ([]int, error) {
+	text = strings.TrimPrefix(text, "v")
+	if i := strings.IndexAny(text, "-+"); i >= 0 {
+		text = text[:i]
+	}
+	if text == "" {
+		return nil, errors.New("empty version")
+	}
+
+	parts := strings.Split(text, ".")
+	version := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", text)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+// compareVersions returns -1, 0 or 1 as a is less than, equal to or greater
+// than b.
+func compareVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// allows reports whether version satisfies every comparison.
+func (vc *versionConstraint) allows(version []int) bool {
+	for _, comparison := range vc.comparisons {
+		cmp := compareVersions(version, comparison.version)
+		var ok bool
+		switch comparison.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckServerVersion checks the service's version against the constraint
+// given to WithServerVersionConstraint, returning an error wrapping
+// ErrIncompatibleServer if it is not satisfied. It returns nil if there is
+// no constraint. Call it at startup to fail early instead of on the first
+// extraction; the version is cached either way.
+func (c *Client) CheckServerVersion(ctx context.Context) error {
+	if c.constraint == nil {
+		return nil
+	}
+
+	text, err := c.discoverVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking server version: %w", err)
+	}
+	version, err := parseVersion(text)
+	if err != nil {
+		return fmt.Errorf("%w: cannot parse server version %q", ErrIncompatibleServer, text)
+	}
+	if !c.constraint.allows(version) {
+		return fmt.Errorf("%w: server version %s does not satisfy %q", ErrIncompatibleServer, text, c.constraint.text)
+	}
+	return nil
+}
+
+// requireVersion checks the service's version against the client's
+// constraint before a request of r. Health checks and capability discovery
+// are not checked, since they are used to discover the version.
+func (c *Client) requireVersion(ctx context.Context, r apiRequest) error {
+	if r.op == OpHealthCheck || r.op == OpCapabilities {
+		return nil
+	}
+	return c.CheckServerVersion(ctx)
+}
@@ -0,0 +1,149 @@
+package pdfclient_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	pdfclient "github.com/mhpenta/pypdftotext-client"
+	"github.com/mhpenta/pypdftotext-client/pdfclienttest"
+)
+
+func TestWithServerVersionConstraint(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		wantErr    bool
+	}{
+		{"1.2.0", ">=1.2 <2", false},
+		{"1.9.3", ">=1.2 <2", false},
+		{"1.1.9", ">=1.2 <2", true},
+		{"2.0.0", ">=1.2 <2", true},
+		{"1.5", ">= 1.2, < 2", false},
+		{"v1.2.0-rc1", ">=1.2", false},
+		{"1.0.0", "1.0", false},
+		{"1.0.1", "==1.0", true},
+		{"1.0.0", "!=1.0", true},
+		{"1.0.0", ">1", true},
+		{"dev", ">=1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.constraint, func(t *testing.T) {
+			server := pdfclienttest.NewServer(pdfclienttest.WithVersion(tt.version))
+			defer server.Close()
+
+			client, err := pdfclient.NewClient(server.URL, pdfclient.WithServerVersionConstraint(tt.constraint))
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			_, err = client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf")
+			if tt.wantErr {
+				if !errors.Is(err, pdfclient.ErrIncompatibleServer) {
+					t.Errorf("ExtractTextFromBytes() error = %v, want ErrIncompatibleServer", err)
+				}
+				if n := countRequests(server, "/extract"); n != 0 {
+					t.Errorf("server received %d uploads, want %d", n, 0)
+				}
+			} else if err != nil {
+				t.Errorf("ExtractTextFromBytes() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWithServerVersionConstraint_Cached(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithVersion("1.2.0"))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithServerVersionConstraint(">=1.2"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf"); err != nil {
+			t.Fatalf("ExtractTextFromBytes() error = %v", err)
+		}
+	}
+
+	if n := countRequests(server, "/health"); n != 1 {
+		t.Errorf("server received %d health checks, want %d", n, 1)
+	}
+}
+
+func TestWithServerVersionConstraint_Concurrent(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithVersion("1.2.0"))
+	defer server.Close()
+
+	client, err := pdfclient.NewClient(server.URL, pdfclient.WithServerVersionConstraint(">=1.2"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// Concurrent calls share one health check.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ExtractTextFromBytes(context.Background(), []byte(testPDF), "test.pdf"); err != nil {
+				t.Errorf("ExtractTextFromBytes() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := countRequests(server, "/health"); n != 1 {
+		t.Errorf("server received %d health checks, want %d", n, 1)
+	}
+}
+
+func TestWithServerVersionConstraint_Invalid(t *testing.T) {
+	for _, constraint := range []string{">=", ">=1.x", "~1.2", ","} {
+		_, err := pdfclient.NewClient("http://localhost:8000", pdfclient.WithServerVersionConstraint(constraint))
+		if err == nil {
+			t.Errorf("NewClient() with constraint %q error = nil, want an error", constraint)
+		}
+	}
+}
+
+func TestCheckServerVersion(t *testing.T) {
+	server := pdfclienttest.NewServer(pdfclienttest.WithVersion("1.1.0"))
+	defer server.Close()
+
+	tests := []struct {
+		constraint string
+		wantErr    bool
+	}{
+		{"", false},
+		{">=1.0", false},
+		{">=1.2", true},
+	}
+	for _, tt := range tests {
+		var options []pdfclient.ClientOption
+		if tt.constraint != "" {
+			options = append(options, pdfclient.WithServerVersionConstraint(tt.constraint))
+		}
+		client, err := pdfclient.NewClient(server.URL, options...)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		err = client.CheckServerVersion(context.Background())
+		if tt.wantErr {
+			if !errors.Is(err, pdfclient.ErrIncompatibleServer) {
+				t.Errorf("CheckServerVersion() with %q error = %v, want ErrIncompatibleServer", tt.constraint, err)
+			}
+		} else if err != nil {
+			t.Errorf("CheckServerVersion() with %q error = %v", tt.constraint, err)
+		}
+	}
+
+	// Without a constraint, nothing is checked.
+	if n := countRequests(server, "/health"); n != 2 {
+		t.Errorf("server received %d health checks, want %d", n, 2)
+	}
+}